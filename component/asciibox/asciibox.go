@@ -16,7 +16,6 @@ import (
 type AsciiBox struct {
 	*ui.Block
 	*data.Consumer
-	alert   *data.Alert
 	ascii   string
 	style   ui.Style
 	render  *fl.AsciiRender
@@ -59,7 +58,7 @@ func NewAsciiBox(c config.AsciiBoxConfig, palette console.Palette) *AsciiBox {
 			case sample := <-box.SampleChannel:
 				box.renderText(sample)
 			case alert := <-box.AlertChannel:
-				box.alert = alert
+				box.SetAlert(alert)
 			}
 		}
 	}()
@@ -94,5 +93,5 @@ func (a *AsciiBox) Draw(buffer *ui.Buffer) {
 		}
 	}
 
-	component.RenderAlert(a.alert, a.Rectangle, buffer)
+	component.RenderAlert(a.GetAlert(), a.Rectangle, buffer)
 }
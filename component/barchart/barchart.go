@@ -47,7 +47,10 @@ func NewBarChart(c config.BarChartConfig, palette console.Palette) *BarChart {
 	}
 
 	for _, i := range c.Items {
-		chart.addBar(*i.Label, *i.Color)
+		// bars for sql row sets are added as soon as rows are received
+		if i.Sql == nil || i.Sql.Rows == nil || *i.Sql.Rows != config.SqlRowsBars {
+			chart.addBar(*i.Label, *i.Color)
+		}
 	}
 
 	go func() {
@@ -56,7 +59,7 @@ func NewBarChart(c config.BarChartConfig, palette console.Palette) *BarChart {
 			case sample := <-chart.SampleChannel:
 				chart.consumeSample(sample)
 			case alert := <-chart.AlertChannel:
-				chart.Alert = alert
+				chart.SetAlert(alert)
 			}
 		}
 	}()
@@ -84,6 +87,11 @@ func (b *BarChart) consumeSample(sample *data.Sample) {
 		}
 	}
 
+	if index < 0 {
+		b.addBar(sample.Label, b.palette.ContentColors[len(b.bars)%len(b.palette.ContentColors)])
+		index = len(b.bars) - 1
+	}
+
 	bar := b.bars[index]
 	bar.delta = float - bar.value
 	bar.value = float
@@ -117,6 +125,11 @@ func (b *BarChart) reselectMaxValue() {
 func (b *BarChart) Draw(buffer *ui.Buffer) {
 	b.Block.Draw(buffer)
 
+	if len(b.bars) == 0 {
+		component.RenderAlert(b.GetAlert(), b.Rectangle, buffer)
+		return
+	}
+
 	barWidth := int(math.Floor(float64(b.Inner.Dx()-len(b.bars)*barIndent) / float64(len(b.bars))))
 	barXCoordinate := b.Inner.Min.X + barIndent
 
@@ -163,5 +176,5 @@ func (b *BarChart) Draw(buffer *ui.Buffer) {
 		barXCoordinate += barWidth + barIndent
 	}
 
-	component.RenderAlert(b.Alert, b.Rectangle, buffer)
+	component.RenderAlert(b.GetAlert(), b.Rectangle, buffer)
 }
@@ -3,28 +3,117 @@ package component
 import (
 	ui "github.com/gizak/termui/v3"
 	"github.com/sqshq/sampler/config"
+	"github.com/sqshq/sampler/console"
 	"github.com/sqshq/sampler/data"
+	"image"
+	"sort"
 )
 
+const pausedText = " PAUSED "
+
+// Component is placed at Location with Size of the current breakpoint.
+// Positions of the other breakpoints are kept to switch back to them
 type Component struct {
 	ui.Drawable
 	*data.Consumer
-	Type     config.ComponentType
-	Title    string
-	Location config.Location
-	Size     config.Size
-	RateMs   int
+	Sampler    *data.Sampler
+	Type       config.ComponentType
+	Title      string
+	Location   config.Location
+	Size       config.Size
+	RateMs     int
+	Source     *config.SourceConfig
+	Weight     int
+	Paused     bool
+	positions  map[string]placement
+	breakpoint string
+}
+
+type placement struct {
+	location config.Location
+	size     config.Size
 }
 
 func NewComponent(dbl ui.Drawable, cmr *data.Consumer, cfg config.ComponentConfig) *Component {
+
+	// default position has an empty breakpoint name
+	positions := map[string]placement{"": {cfg.GetLocation(), cfg.GetSize()}}
+	for name := range cfg.Positions {
+		if location, size, ok := cfg.GetPositionAt(name); ok {
+			positions[name] = placement{location, size}
+		}
+	}
+
 	return &Component{
-		Drawable: dbl,
-		Consumer: cmr,
-		Type:     cfg.Type,
-		Title:    cfg.Title,
-		Location: cfg.GetLocation(),
-		Size:     cfg.GetSize(),
-		RateMs:   *cfg.RateMs,
+		Drawable:  dbl,
+		Consumer:  cmr,
+		Type:      cfg.Type,
+		Title:     cfg.Title,
+		Location:  cfg.GetLocation(),
+		Size:      cfg.GetSize(),
+		RateMs:    *cfg.RateMs,
+		Source:    cfg.Source,
+		Weight:    cfg.GetWeight(),
+		positions: positions,
+	}
+}
+
+// SetBreakpoint places the component at the breakpoint position, or at the default one,
+// if the component has no position for the breakpoint
+func (c *Component) SetBreakpoint(breakpoint string) {
+
+	c.keepPosition()
+	c.breakpoint = breakpoint
+
+	p, ok := c.positions[breakpoint]
+	if !ok {
+		p = c.positions[""]
+	}
+
+	c.Location, c.Size = p.location, p.size
+}
+
+func (c *Component) GetBreakpoint() string {
+	return c.breakpoint
+}
+
+// GetPositions returns settings with the component positions for all the breakpoints
+func (c *Component) GetPositions() []config.ComponentSettings {
+
+	c.keepPosition()
+
+	var names []string
+	for name := range c.positions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var settings []config.ComponentSettings
+	for _, name := range names {
+		p := c.positions[name]
+		settings = append(settings, config.ComponentSettings{
+			Type: c.Type, Title: c.Title, Location: p.location, Size: p.size, Breakpoint: name})
+	}
+
+	return settings
+}
+
+// keepPosition saves the current position for the current breakpoint. The default position,
+// used for the breakpoint, is saved only if it was changed
+func (c *Component) keepPosition() {
+	current := placement{c.Location, c.Size}
+	if _, ok := c.positions[c.breakpoint]; ok || current != c.positions[""] {
+		c.positions[c.breakpoint] = current
+	}
+}
+
+// Draw marks the component, which sampling is paused individually
+func (c *Component) Draw(buffer *ui.Buffer) {
+
+	c.Drawable.Draw(buffer)
+
+	if r := c.GetRect(); c.Paused && r.Dx() > len(pausedText)+4 {
+		buffer.SetString(pausedText, ui.NewStyle(console.ColorBlack, console.ColorOlive), image.Pt(r.Max.X-len(pausedText)-2, r.Min.Y))
 	}
 }
 
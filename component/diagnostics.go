@@ -0,0 +1,157 @@
+package component
+
+import (
+	"fmt"
+	ui "github.com/gizak/termui/v3"
+	"github.com/sqshq/sampler/console"
+	"image"
+	"strconv"
+	"time"
+)
+
+// Diagnostics shows sampling stats of each item, which are updated on every render
+type Diagnostics struct {
+	*ui.Block
+	palette    console.Palette
+	keys       console.KeyMap
+	components []*Component
+}
+
+// DiagnosticsRow is a table row with an item stats. Warning marks the items with
+// failures, executions longer than the sampling rate, or samples dropped, since the component was behind
+type DiagnosticsRow struct {
+	Cells   []string
+	Warning bool
+}
+
+var diagnosticsHeader = []string{"COMPONENT", "ITEM", "RATE", "LAST", "AVG", "RUNS", "FAILED", "SLOW", "DROPPED", "QUEUE", "TRIG DROPPED"}
+
+const (
+	diagnosticsMaxTitleWidth = 24
+	diagnosticsColumnGap     = 2
+)
+
+func NewDiagnostics(palette console.Palette, keys console.KeyMap) *Diagnostics {
+	return &Diagnostics{
+		Block:   NewBlock("DIAGNOSTICS", true, palette),
+		palette: palette,
+		keys:    keys,
+	}
+}
+
+// Open keeps the components, which stats are shown
+func (d *Diagnostics) Open(components []*Component) {
+	d.components = components
+}
+
+// GetDiagnosticsRows returns the header and the stats of each item. Component title,
+// rate, queue and the samples dropped by the triggers are shown in the row of its first item
+func GetDiagnosticsRows(components []*Component) []DiagnosticsRow {
+
+	rows := []DiagnosticsRow{{Cells: diagnosticsHeader}}
+
+	for _, c := range components {
+		if c.Sampler == nil {
+			continue
+		}
+		stats := c.Sampler.GetStats()
+		for i, item := range stats.Items {
+			title, rate, queue, triggers := "", "", "", ""
+			if i == 0 {
+				title, rate, queue = c.Title, getRateText(c), fmt.Sprintf("%d/%d", stats.Queue, stats.QueueCap)
+				triggers = strconv.Itoa(stats.TriggersDropped)
+			}
+			rows = append(rows, DiagnosticsRow{
+				Cells: []string{title, item.Label, rate, formatDuration(item.LastDuration), formatDuration(item.AvgDuration),
+					strconv.Itoa(item.Executions), strconv.Itoa(item.Failures), strconv.Itoa(item.Timeouts), strconv.Itoa(item.Dropped), queue, triggers},
+				Warning: item.Failures > 0 || item.Timeouts > 0 || item.Dropped > 0 || i == 0 && stats.TriggersDropped > 0,
+			})
+		}
+	}
+
+	return rows
+}
+
+// formatDuration rounds the duration to milliseconds, or to microseconds for the fast queries
+func formatDuration(d time.Duration) string {
+	switch {
+	case d == 0:
+		return "-"
+	case d < time.Millisecond:
+		return d.Round(time.Microsecond).String()
+	default:
+		return d.Round(time.Millisecond).String()
+	}
+}
+
+// getColumnWidths returns the max width of each column, where titles and labels are limited
+func getColumnWidths(rows []DiagnosticsRow) []int {
+	widths := make([]int, len(diagnosticsHeader))
+	for _, row := range rows {
+		for i, cell := range row.Cells {
+			widths[i] = ui.MaxInt(widths[i], len([]rune(cell)))
+		}
+	}
+	widths[0] = ui.MinInt(widths[0], diagnosticsMaxTitleWidth)
+	widths[1] = ui.MinInt(widths[1], diagnosticsMaxTitleWidth)
+	return widths
+}
+
+// SetArea places the diagnostics in the middle of the area, taking as much space as the table needs
+func (d *Diagnostics) SetArea(area image.Rectangle) {
+
+	rows := GetDiagnosticsRows(d.components)
+
+	width := 2 + diagnosticsColumnGap
+	for _, w := range getColumnWidths(rows) {
+		width += w + diagnosticsColumnGap
+	}
+
+	width = ui.MinInt(width, area.Dx())
+	height := ui.MinInt(len(rows)+4, area.Dy())
+	x := area.Min.X + (area.Dx()-width)/2
+	y := area.Min.Y + (area.Dy()-height)/2
+	d.SetRect(x, y, x+width, y+height)
+}
+
+func (d *Diagnostics) Draw(buffer *ui.Buffer) {
+
+	buffer.Fill(ui.NewCell(' ', ui.NewStyle(d.palette.ReverseColor)), d.GetRect())
+	d.Block.Draw(buffer)
+
+	rows := GetDiagnosticsRows(d.components)
+	widths := getColumnWidths(rows)
+
+	headerStyle := ui.NewStyle(d.palette.BaseColor, d.palette.ReverseColor, ui.ModifierBold)
+	regularStyle := ui.NewStyle(d.palette.BaseColor, d.palette.ReverseColor)
+	warningStyle := ui.NewStyle(console.ColorOrange, d.palette.ReverseColor)
+
+	// the last line is left for the hint
+	height := d.Inner.Dy() - 2
+	if len(rows) > height && height > 1 {
+		hidden := len(rows) - height + 1
+		rows = append(rows[:height-1], DiagnosticsRow{Cells: []string{fmt.Sprintf("+%d more", hidden)}})
+	}
+
+	for i, row := range rows {
+		if i >= height {
+			break
+		}
+		style := regularStyle
+		if i == 0 {
+			style = headerStyle
+		} else if row.Warning {
+			style = warningStyle
+		}
+		x := d.Inner.Min.X + diagnosticsColumnGap
+		for j, cell := range row.Cells {
+			if width := d.Inner.Max.X - x; width > 0 {
+				buffer.SetString(ui.TrimString(cell, ui.MinInt(widths[j], width)), style, image.Pt(x, d.Inner.Min.Y+1+i))
+			}
+			x += widths[j] + diagnosticsColumnGap
+		}
+	}
+
+	hint := fmt.Sprintf("<%s> or <%s> to close", d.keys.GetHint(console.ActionStats), d.keys.GetHint(console.ActionReset))
+	buffer.SetString(hint, ui.NewStyle(console.ColorDarkGrey), image.Pt(d.Inner.Max.X-len(hint)-diagnosticsColumnGap, d.Inner.Max.Y-1))
+}
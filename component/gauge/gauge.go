@@ -47,7 +47,7 @@ func NewGauge(c config.GaugeConfig, palette console.Palette) *Gauge {
 			case sample := <-g.SampleChannel:
 				g.ConsumeSample(sample)
 			case alert := <-g.AlertChannel:
-				g.Alert = alert
+				g.SetAlert(alert)
 			}
 		}
 	}()
@@ -116,7 +116,7 @@ func (g *Gauge) Draw(buffer *ui.Buffer) {
 		}
 	}
 
-	component.RenderAlert(g.Alert, g.Rectangle, buffer)
+	component.RenderAlert(g.GetAlert(), g.Rectangle, buffer)
 }
 
 func calculatePercent(g *Gauge) float64 {
@@ -0,0 +1,167 @@
+package component
+
+import (
+	"fmt"
+	ui "github.com/gizak/termui/v3"
+	"github.com/sqshq/sampler/console"
+	"image"
+	"strings"
+	"time"
+)
+
+// Help lists the actions with the bound keys by mode, and describes the running config
+type Help struct {
+	*ui.Block
+	palette        console.Palette
+	keys           console.KeyMap
+	configFileName string
+	components     []*Component
+	sections       []HelpSection
+}
+
+// HelpBinding describes the keys of the actions, or the menu option
+type HelpBinding struct {
+	Actions     []console.Action
+	Option      MenuOption
+	Description string
+}
+
+// HelpSection lists the bindings, available in the mode
+type HelpSection struct {
+	Name     string
+	Bindings []HelpBinding
+}
+
+type helpLine struct {
+	key    string
+	text   string
+	header bool
+}
+
+const (
+	helpKeyWidth     = 16
+	helpBindingWidth = 48
+	helpConfigWidth  = 40
+	helpPadding      = 2
+)
+
+func NewHelp(configFileName string, palette console.Palette, keys console.KeyMap) *Help {
+	return &Help{
+		Block:          NewBlock("HELP", true, palette),
+		palette:        palette,
+		keys:           keys,
+		configFileName: configFileName,
+	}
+}
+
+// SetSections keeps the bindings, which are handled by the layout in each mode
+func (h *Help) SetSections(sections []HelpSection) {
+	h.sections = sections
+}
+
+// Open keeps the components, which are described in the help
+func (h *Help) Open(components []*Component) {
+	h.components = components
+}
+
+// SetArea places the help in the middle of the area, taking as much space as needed
+func (h *Help) SetArea(area image.Rectangle) {
+	width := ui.MinInt(helpBindingWidth+helpConfigWidth+helpPadding*3+2, area.Dx())
+	height := ui.MinInt(ui.MaxInt(len(h.getBindingLines()), len(h.getConfigLines(-1)))+4, area.Dy())
+	x := area.Min.X + (area.Dx()-width)/2
+	y := area.Min.Y + (area.Dy()-height)/2
+	h.SetRect(x, y, x+width, y+height)
+}
+
+func (h *Help) Draw(buffer *ui.Buffer) {
+
+	buffer.Fill(ui.NewCell(' ', ui.NewStyle(h.palette.ReverseColor)), h.GetRect())
+	h.Block.Draw(buffer)
+
+	// the last line is left for the hint
+	height := h.Inner.Dy() - 2
+	x, y := h.Inner.Min.X+helpPadding, h.Inner.Min.Y+1
+
+	h.drawLines(buffer, h.getBindingLines(), image.Pt(x, y), helpBindingWidth, height)
+	h.drawLines(buffer, h.getConfigLines(height), image.Pt(x+helpBindingWidth+helpPadding, y), h.Inner.Max.X-x-helpBindingWidth-helpPadding*2, height)
+
+	hint := fmt.Sprintf("<%s> or <%s> to close", h.keys.GetHint(console.ActionHelp), h.keys.GetHint(console.ActionReset))
+	buffer.SetString(hint, ui.NewStyle(console.ColorDarkGrey), image.Pt(h.Inner.Max.X-len(hint)-helpPadding, h.Inner.Max.Y-1))
+}
+
+func (h *Help) drawLines(buffer *ui.Buffer, lines []helpLine, point image.Point, width int, height int) {
+
+	regularStyle := ui.NewStyle(h.palette.BaseColor, h.palette.ReverseColor)
+	keyStyle := ui.NewStyle(console.ColorOlive, h.palette.ReverseColor)
+	headerStyle := ui.NewStyle(h.palette.BaseColor, h.palette.ReverseColor, ui.ModifierBold)
+
+	for i, line := range lines {
+		if i >= height || width <= 0 {
+			return
+		}
+		p := image.Pt(point.X, point.Y+i)
+		if line.header {
+			buffer.SetString(ui.TrimString(line.text, width), headerStyle, p)
+			continue
+		}
+		buffer.SetString(ui.TrimString(line.key, ui.MinInt(helpKeyWidth-1, width)), keyStyle, p)
+		if width > helpKeyWidth {
+			buffer.SetString(ui.TrimString(line.text, width-helpKeyWidth), regularStyle, p.Add(image.Pt(helpKeyWidth, 0)))
+		}
+	}
+}
+
+// getBindingLines returns the sections with the actions, available in each mode. Sections aren't
+// separated with blank lines, so all of them fit the default 40 rows along with the menu options
+func (h *Help) getBindingLines() []helpLine {
+
+	var lines []helpLine
+
+	for _, section := range h.sections {
+		lines = append(lines, helpLine{text: section.Name, header: true})
+		for _, binding := range section.Bindings {
+			key := string(binding.Option)
+			if len(binding.Actions) > 0 {
+				var keys []string
+				for _, action := range binding.Actions {
+					keys = append(keys, h.keys.GetKeys(action))
+				}
+				key = strings.Join(keys, " ")
+			}
+			lines = append(lines, helpLine{key: key, text: binding.Description})
+		}
+	}
+
+	return lines
+}
+
+// getConfigLines returns the config file location and the sampling rates, which fit the height.
+// Negative height means no limit
+func (h *Help) getConfigLines(height int) []helpLine {
+
+	lines := []helpLine{
+		{text: "CONFIG", header: true},
+		{text: h.configFileName},
+		{},
+		{text: fmt.Sprintf("COMPONENTS: %d", len(h.components)), header: true},
+	}
+
+	for _, c := range h.components {
+		lines = append(lines, helpLine{key: getRateText(c), text: c.Title})
+	}
+
+	if height > 0 && len(lines) > height {
+		hidden := len(lines) - height + 1
+		lines = append(lines[:height-1], helpLine{text: fmt.Sprintf("+%d more", hidden)})
+	}
+
+	return lines
+}
+
+// getRateText returns the sampling rate, or 'push' for the components with a push source
+func getRateText(c *Component) string {
+	if c.Source != nil {
+		return "push"
+	}
+	return (time.Duration(c.RateMs) * time.Millisecond).String()
+}
@@ -0,0 +1,291 @@
+package layout
+
+import (
+	"github.com/sqshq/sampler/component"
+	"github.com/sqshq/sampler/component/runchart"
+	"github.com/sqshq/sampler/config"
+	"github.com/sqshq/sampler/console"
+	"github.com/sqshq/sampler/data"
+)
+
+// binding is an action, which is handled in the section modes. The same description is shown in the help
+type binding struct {
+	actions     []console.Action
+	description string
+	handle      func(l *Layout, action console.Action)
+}
+
+// option is a menu option, which is applied to the selected component
+type option struct {
+	option      component.MenuOption
+	description string
+	apply       func(l *Layout, selected *component.Component)
+}
+
+// section lists the bindings of the modes, or of all the modes, if none are specified
+type section struct {
+	name     string
+	modes    []Mode
+	bindings []binding
+	options  []option
+}
+
+var directions = []console.Action{console.ActionLeft, console.ActionRight, console.ActionUp, console.ActionDown}
+
+// sections is the only list of the keyboard actions, which both the event handling and the help are built from
+var sections = []section{
+	{"ANY MODE", nil, []binding{
+		{[]console.Action{console.ActionPause}, "pause or resume sampling", (*Layout).togglePause},
+		{[]console.Action{console.ActionReset}, "reset alerts, leave the mode", (*Layout).reset},
+		{[]console.Action{console.ActionHelp}, "show help", (*Layout).openHelp},
+		{[]console.Action{console.ActionStats}, "show sampling stats of each item", (*Layout).openStats},
+		// quit is handled before the layout, since the changes are saved on quit
+		{[]console.Action{console.ActionQuit}, "quit, saving the changes", nil},
+	}, nil},
+	{"DEFAULT", []Mode{ModeDefault}, []binding{
+		{directions, "select a component", (*Layout).startSelection},
+		{[]console.Action{console.ActionAdd}, "add a component", (*Layout).openWizard},
+	}, nil},
+	{"SELECT", []Mode{ModeComponentSelect}, []binding{
+		{directions, "select the next component", (*Layout).selectNext},
+		{[]console.Action{console.ActionSelect}, "view the menu options", (*Layout).openMenu},
+	}, nil},
+	{"MENU", []Mode{ModeMenuOptionSelect}, []binding{
+		{[]console.Action{console.ActionUp, console.ActionDown}, "choose the menu option", (*Layout).chooseOption},
+		{[]console.Action{console.ActionSelect}, "apply the menu option:", (*Layout).applyOption},
+	}, menuOptions},
+	{"DELETE", []Mode{ModeComponentDelete}, []binding{
+		{[]console.Action{console.ActionSelect}, "confirm the deletion", (*Layout).deleteComponent},
+	}, nil},
+	{"MOVE", []Mode{ModeComponentMove}, []binding{
+		{directions, "move the component", (*Layout).moveComponent},
+		{[]console.Action{console.ActionSelect}, "save the position", (*Layout).closeMenu},
+	}, nil},
+	{"RESIZE", []Mode{ModeComponentResize}, []binding{
+		{directions, "resize the component", (*Layout).resizeComponent},
+		{[]console.Action{console.ActionSelect}, "save the size", (*Layout).closeMenu},
+	}, nil},
+	{"PINPOINT", []Mode{ModeChartPinpoint}, []binding{
+		{[]console.Action{console.ActionLeft, console.ActionRight}, "move the pinpoint", (*Layout).movePinpoint},
+	}, nil},
+}
+
+// menuOptions are applied with the select key in the menu, and listed in the help after its bindings
+var menuOptions = []option{
+	{component.MenuOptionMove, "move the component", (*Layout).startMove},
+	{component.MenuOptionResize, "resize the component", (*Layout).startResize},
+	{component.MenuOptionPinpoint, "pinpoint the run chart values", (*Layout).startPinpoint},
+	{component.MenuOptionPause, "pause or resume the component", (*Layout).pauseComponent},
+	{component.MenuOptionRefresh, "sample the component right away", (*Layout).refreshComponent},
+	{component.MenuOptionDuplicate, "add a copy, saved on quit", (*Layout).duplicateComponent},
+	{component.MenuOptionDelete, "delete, saved on quit", (*Layout).confirmDelete},
+	{component.MenuOptionTile, "tile all the components", (*Layout).tileComponents},
+	{component.MenuOptionResume, "close the menu", (*Layout).resume},
+}
+
+// findBinding returns the binding of the action, which is handled in the current mode
+func (l *Layout) findBinding(action console.Action) (binding, bool) {
+	for _, s := range sections {
+		if len(s.modes) > 0 && !containsMode(s.modes, l.mode) {
+			continue
+		}
+		for _, b := range s.bindings {
+			for _, a := range b.actions {
+				if a == action {
+					return b, true
+				}
+			}
+		}
+	}
+	return binding{}, false
+}
+
+func containsMode(modes []Mode, mode Mode) bool {
+	for _, m := range modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// getHelpSections describes the bindings and the menu options for the help
+func getHelpSections() []component.HelpSection {
+	var result []component.HelpSection
+	for _, s := range sections {
+		hs := component.HelpSection{Name: s.name}
+		for _, b := range s.bindings {
+			hs.Bindings = append(hs.Bindings, component.HelpBinding{Actions: b.actions, Description: b.description})
+		}
+		for _, o := range s.options {
+			hs.Bindings = append(hs.Bindings, component.HelpBinding{Option: o.option, Description: o.description})
+		}
+		result = append(result, hs)
+	}
+	return result
+}
+
+func (l *Layout) togglePause(console.Action) {
+	if l.mode == ModePause {
+		l.changeMode(ModeDefault)
+		l.statusbar.TogglePause()
+		return
+	}
+	if selected := l.getSelection(); selected.Type == config.TypeRunChart {
+		selected.PushCommand(&data.Command{Type: runchart.CommandDisableSelection})
+	}
+	l.menu.Idle()
+	l.changeMode(ModePause)
+	l.statusbar.TogglePause()
+}
+
+// reset keeps the pause, since it's resumed with the pause key only
+func (l *Layout) reset(console.Action) {
+	l.resetAlerts()
+	switch l.mode {
+	case ModeChartPinpoint:
+		l.getSelection().PushCommand(&data.Command{Type: runchart.CommandDisableSelection})
+		l.changeMode(ModeDefault)
+	case ModeComponentSelect, ModeMenuOptionSelect, ModeComponentDelete, ModeComponentMove, ModeComponentResize:
+		l.closeMenu(console.ActionReset)
+	}
+}
+
+func (l *Layout) openHelp(console.Action) {
+	l.openOverlay(ModeHelp)
+}
+
+func (l *Layout) openStats(console.Action) {
+	l.openOverlay(ModeDiagnostics)
+}
+
+func (l *Layout) startSelection(console.Action) {
+	l.changeMode(ModeComponentSelect)
+	l.menu.Highlight(l.getComponent(l.selection))
+}
+
+func (l *Layout) openWizard(console.Action) {
+	l.wizard.Open()
+	l.changeMode(ModeComponentAdd)
+}
+
+func (l *Layout) selectNext(action console.Action) {
+	l.moveSelection(action)
+	l.menu.Highlight(l.getComponent(l.selection))
+}
+
+func (l *Layout) openMenu(console.Action) {
+	l.menu.Choose()
+	l.changeMode(ModeMenuOptionSelect)
+}
+
+func (l *Layout) chooseOption(action console.Action) {
+	if action == console.ActionUp {
+		l.menu.Up()
+	} else {
+		l.menu.Down()
+	}
+}
+
+func (l *Layout) applyOption(console.Action) {
+	chosen := l.menu.GetSelectedOption()
+	for _, o := range menuOptions {
+		if o.option == chosen {
+			o.apply(l, l.getSelection())
+		}
+	}
+}
+
+func (l *Layout) deleteComponent(console.Action) {
+	if len(l.Components) > 1 {
+		l.menu.Idle()
+		l.changeMode(ModeDefault)
+		l.DeleteComponentEvents <- l.getSelection()
+	}
+}
+
+// moveComponent moves the selected component by one cell in the direction
+func (l *Layout) moveComponent(action console.Action) {
+	x, y := getOffset(action)
+	l.getSelection().Move(x, y)
+}
+
+// resizeComponent changes the selected component size by one cell in the direction
+func (l *Layout) resizeComponent(action console.Action) {
+	x, y := getOffset(action)
+	l.getSelection().Resize(x, y)
+}
+
+func (l *Layout) movePinpoint(action console.Action) {
+	x, _ := getOffset(action)
+	l.getSelection().PushCommand(&data.Command{Type: runchart.CommandMoveSelection, Value: x})
+}
+
+func getOffset(direction console.Action) (int, int) {
+	switch direction {
+	case console.ActionLeft:
+		return -1, 0
+	case console.ActionRight:
+		return 1, 0
+	case console.ActionUp:
+		return 0, -1
+	case console.ActionDown:
+		return 0, 1
+	}
+	return 0, 0
+}
+
+func (l *Layout) startMove(*component.Component) {
+	l.changeMode(ModeComponentMove)
+	l.menu.MoveOrResize()
+}
+
+func (l *Layout) startResize(*component.Component) {
+	l.changeMode(ModeComponentResize)
+	l.menu.MoveOrResize()
+}
+
+func (l *Layout) startPinpoint(selected *component.Component) {
+	l.changeMode(ModeChartPinpoint)
+	l.menu.Idle()
+	selected.PushCommand(&data.Command{Type: runchart.CommandMoveSelection, Value: 0})
+}
+
+func (l *Layout) pauseComponent(selected *component.Component) {
+	l.menu.Idle()
+	l.changeMode(ModeDefault)
+	l.PauseComponentEvents <- selected
+}
+
+func (l *Layout) refreshComponent(selected *component.Component) {
+	l.menu.Idle()
+	l.changeMode(ModeDefault)
+	l.RefreshComponentEvents <- selected
+}
+
+func (l *Layout) duplicateComponent(selected *component.Component) {
+	l.menu.Idle()
+	l.changeMode(ModeDefault)
+	l.DuplicateComponentEvents <- selected
+}
+
+func (l *Layout) confirmDelete(*component.Component) {
+	l.menu.ConfirmDelete(len(l.Components) > 1)
+	l.changeMode(ModeComponentDelete)
+}
+
+func (l *Layout) tileComponents(*component.Component) {
+	l.tile()
+	l.menu.Idle()
+	l.changeMode(ModeDefault)
+}
+
+// closeMenu returns to the default mode, keeping the position and the size of the component
+func (l *Layout) closeMenu(console.Action) {
+	l.menu.Idle()
+	l.changeMode(ModeDefault)
+}
+
+func (l *Layout) resume(*component.Component) {
+	l.closeMenu(console.ActionSelect)
+}
@@ -0,0 +1,27 @@
+package layout
+
+import "testing"
+
+// each action should be handled once in a mode, otherwise the help describes the binding, which is never used
+func TestSections_unique(t *testing.T) {
+
+	modes := []Mode{ModeDefault, ModePause, ModeComponentSelect, ModeMenuOptionSelect, ModeComponentMove,
+		ModeComponentResize, ModeChartPinpoint, ModeComponentDelete}
+
+	for _, mode := range modes {
+		bound := make(map[string]string)
+		for _, s := range sections {
+			if len(s.modes) > 0 && !containsMode(s.modes, mode) {
+				continue
+			}
+			for _, b := range s.bindings {
+				for _, a := range b.actions {
+					if other, ok := bound[string(a)]; ok {
+						t.Errorf("mode %d: '%s' is bound to both '%s' and '%s'", mode, a, other, b.description)
+					}
+					bound[string(a)] = b.description
+				}
+			}
+		}
+	}
+}
@@ -1,9 +1,11 @@
 package layout
 
 import (
+	"fmt"
 	ui "github.com/gizak/termui/v3"
 	"github.com/sqshq/sampler/component"
 	"github.com/sqshq/sampler/component/runchart"
+	"github.com/sqshq/sampler/component/textbox"
 	"github.com/sqshq/sampler/component/util"
 	"github.com/sqshq/sampler/config"
 	"github.com/sqshq/sampler/console"
@@ -16,14 +18,40 @@ import (
 // Layout represents component arrangement on the screen
 type Layout struct {
 	ui.Block
-	Components       []*component.Component
-	statusbar        *component.StatusBar
-	menu             *component.Menu
-	ChangeModeEvents chan Mode
-	mode             Mode
-	selection        int
-	positionsChanged bool
-	startupTime      time.Time
+	Components               []*component.Component
+	statusbar                *component.StatusBar
+	menu                     *component.Menu
+	wizard                   *component.Wizard
+	help                     *component.Help
+	diagnostics              *component.Diagnostics
+	ChangeModeEvents         chan Mode
+	AddComponentEvents       chan config.ComponentDraft
+	DuplicateComponentEvents chan *component.Component
+	DeleteComponentEvents    chan *component.Component
+	PauseComponentEvents     chan *component.Component
+	RefreshComponentEvents   chan *component.Component
+	mode                     Mode
+	overlayReturnMode        Mode
+	selection                int
+	positionsChanged         bool
+	startupTime              time.Time
+	drag                     *mouseDrag
+	hovered                  *component.Component
+	grid                     config.GridConfig
+	breakpoints              map[string]int
+	breakpoint               string
+	tiling                   config.Tiling
+	auto                     bool
+	keys                     console.KeyMap
+}
+
+// mouseDrag is started by a click on the component title to move it,
+// or on the bottom right corner to resize it
+type mouseDrag struct {
+	mode     Mode
+	start    image.Point
+	location config.Location
+	size     config.Size
 }
 
 type Mode rune
@@ -37,33 +65,55 @@ const (
 	ModeComponentMove    Mode = 5
 	ModeComponentResize  Mode = 6
 	ModeChartPinpoint    Mode = 7
+	ModeComponentAdd     Mode = 8
+	ModeComponentDelete  Mode = 9
+	ModeHelp             Mode = 10
+	ModeDiagnostics      Mode = 11
 )
 
 const (
 	minDimension    = 3
 	statusbarHeight = 1
+	cornerSize      = 2
 )
 
-func NewLayout(statusline *component.StatusBar, menu *component.Menu) *Layout {
+func NewLayout(statusline *component.StatusBar, menu *component.Menu, wizard *component.Wizard, help *component.Help,
+	diagnostics *component.Diagnostics, cfg *config.Config) *Layout {
 
 	width, height := ui.TerminalDimensions()
 	block := *ui.NewBlock()
 	block.SetRect(0, 0, width, height)
 	statusline.SetRect(0, height-statusbarHeight, width, height)
+	help.SetSections(getHelpSections())
 
 	return &Layout{
-		Block:            block,
-		Components:       make([]*component.Component, 0),
-		statusbar:        statusline,
-		menu:             menu,
-		mode:             ModeDefault,
-		selection:        0,
-		ChangeModeEvents: make(chan Mode, 10),
-		startupTime:      time.Now(),
+		Block:                    block,
+		Components:               make([]*component.Component, 0),
+		statusbar:                statusline,
+		menu:                     menu,
+		wizard:                   wizard,
+		help:                     help,
+		diagnostics:              diagnostics,
+		mode:                     ModeDefault,
+		selection:                0,
+		ChangeModeEvents:         make(chan Mode, 10),
+		AddComponentEvents:       make(chan config.ComponentDraft, 1),
+		DuplicateComponentEvents: make(chan *component.Component, 1),
+		DeleteComponentEvents:    make(chan *component.Component, 1),
+		PauseComponentEvents:     make(chan *component.Component, 1),
+		RefreshComponentEvents:   make(chan *component.Component, 1),
+		startupTime:              time.Now(),
+		grid:                     cfg.GetGrid(),
+		breakpoints:              cfg.Breakpoints,
+		breakpoint:               config.GetBreakpoint(cfg.Breakpoints, width),
+		tiling:                   cfg.GetTiling(),
+		auto:                     cfg.IsAutoLayout(),
+		keys:                     cfg.GetKeys(),
 	}
 }
 
 func (l *Layout) AddComponent(cpt *component.Component) {
+	cpt.SetBreakpoint(l.breakpoint)
 	l.Components = append(l.Components, cpt)
 }
 
@@ -75,15 +125,31 @@ func (l *Layout) changeMode(m Mode) {
 	if m == ModeComponentResize || m == ModeComponentMove {
 		l.positionsChanged = true
 	}
+	if m != ModeDefault {
+		l.stopHover()
+	}
 	l.mode = m
 	l.ChangeModeEvents <- m
 }
 
+// GetMode returns the current mode, e.g. to check the initial one, which wasn't sent as a change
+func (l *Layout) GetMode() Mode {
+	return l.mode
+}
+
 func (l *Layout) HandleMouseClick(x int, y int) {
-	if l.mode == ModeIntro {
+	if l.mode == ModeIntro || l.mode == ModeComponentAdd {
 		return
 	}
+	if l.isOverlayMode() {
+		l.closeOverlay()
+		return
+	}
+	if l.mode == ModeChartPinpoint {
+		l.getSelection().PushCommand(&data.Command{Type: runchart.CommandDisableSelection})
+	}
 	l.menu.Idle()
+	l.drag = nil
 	selected, i := l.findComponentAtPoint(image.Point{X: x, Y: y})
 	if selected == nil {
 		l.changeMode(ModeDefault)
@@ -91,137 +157,266 @@ func (l *Layout) HandleMouseClick(x int, y int) {
 		l.selection = i
 		l.menu.Highlight(selected)
 		l.changeMode(ModeComponentSelect)
+		l.startDrag(selected, image.Pt(x, y))
 	}
 }
 
-func (l *Layout) HandleKeyboardEvent(e string) {
+func (l *Layout) startDrag(selected *component.Component, point image.Point) {
+
+	rectangle := selected.GetRect()
+
+	if point.Y == rectangle.Min.Y {
+		l.drag = &mouseDrag{mode: ModeComponentMove}
+	} else if point.X >= rectangle.Max.X-cornerSize && point.Y >= rectangle.Max.Y-cornerSize {
+		l.drag = &mouseDrag{mode: ModeComponentResize}
+	} else {
+		return
+	}
+
+	l.drag.start = point
+	l.drag.location = selected.Location
+	l.drag.size = selected.Size
+}
+
+// HandleMouseDrag moves or resizes the selected component, snapping it to the grid
+func (l *Layout) HandleMouseDrag(x int, y int) {
+
+	if l.drag == nil || l.isOverlayMode() {
+		return
+	}
+
+	if l.mode != l.drag.mode {
+		l.menu.Idle()
+		l.changeMode(l.drag.mode)
+	}
+
+	columnWidth, rowHeight := l.getCellSize()
+	dx := int(math.Round(float64(x-l.drag.start.X) / columnWidth))
+	dy := int(math.Round(float64(y-l.drag.start.Y) / rowHeight))
 
 	selected := l.getSelection()
 
-	switch e {
-	case console.KeyPause1, console.KeyPause2:
-		if l.mode == ModePause {
-			l.changeMode(ModeDefault)
-			l.statusbar.TogglePause()
-		} else {
-			if selected.Type == config.TypeRunChart {
-				selected.CommandChannel <- &data.Command{Type: runchart.CommandDisableSelection}
-			}
-			l.menu.Idle()
-			l.changeMode(ModePause)
-			l.statusbar.TogglePause()
-		}
-	case console.KeyEnter:
-		switch l.mode {
-		case ModeComponentSelect:
-			l.menu.Choose()
-			l.changeMode(ModeMenuOptionSelect)
-		case ModeMenuOptionSelect:
-			option := l.menu.GetSelectedOption()
-			switch option {
-			case component.MenuOptionMove:
-				l.changeMode(ModeComponentMove)
-				l.menu.MoveOrResize()
-			case component.MenuOptionResize:
-				l.changeMode(ModeComponentResize)
-				l.menu.MoveOrResize()
-			case component.MenuOptionPinpoint:
-				l.changeMode(ModeChartPinpoint)
-				l.menu.Idle()
-				selected.CommandChannel <- &data.Command{Type: runchart.CommandMoveSelection, Value: 0}
-			case component.MenuOptionResume:
-				l.changeMode(ModeDefault)
-				l.menu.Idle()
-			}
-		case ModeComponentMove:
-			fallthrough
-		case ModeComponentResize:
-			l.menu.Idle()
-			l.changeMode(ModeDefault)
+	switch l.drag.mode {
+	case ModeComponentMove:
+		selected.Location.X = ui.MaxInt(0, ui.MinInt(l.drag.location.X+dx, l.grid.Columns-selected.Size.X))
+		selected.Location.Y = ui.MaxInt(0, ui.MinInt(l.drag.location.Y+dy, l.grid.Rows-selected.Size.Y))
+	case ModeComponentResize:
+		selected.Size.X = ui.MaxInt(1, ui.MinInt(l.drag.size.X+dx, l.grid.Columns-selected.Location.X))
+		selected.Size.Y = ui.MaxInt(1, ui.MinInt(l.drag.size.Y+dy, l.grid.Rows-selected.Location.Y))
+	}
+}
+
+func (l *Layout) HandleMouseRelease() {
+	if l.drag != nil && l.mode == l.drag.mode {
+		l.changeMode(ModeDefault)
+	}
+	l.drag = nil
+}
+
+// HandleMouseHover pinpoints the run chart under the pointer, and stops pinpointing, when the pointer leaves it.
+// Mode is kept, since the pointer enters and leaves the charts much more often, than the mode is switched
+func (l *Layout) HandleMouseHover(x int, y int) {
+
+	if l.mode != ModeDefault {
+		return
+	}
+
+	hovered, _ := l.findComponentAtPoint(image.Point{X: x, Y: y})
+	if hovered != nil && hovered.Type != config.TypeRunChart {
+		hovered = nil
+	}
+
+	if l.hovered != hovered {
+		l.stopHover()
+	}
+
+	if hovered != nil {
+		l.hovered = hovered
+		hovered.PushCommand(&data.Command{Type: runchart.CommandPinpointAt, Value: x})
+	}
+}
+
+// stopHover disables pinpointing of the chart, which was under the pointer
+func (l *Layout) stopHover() {
+	if l.hovered != nil {
+		l.hovered.PushCommand(&data.Command{Type: runchart.CommandDisableSelection})
+		l.hovered = nil
+	}
+}
+
+// HandleMouseWheel scrolls the text box under the pointer
+func (l *Layout) HandleMouseWheel(x int, y int, shift int) {
+
+	if l.mode == ModeIntro || l.mode == ModeComponentAdd || l.isOverlayMode() {
+		return
+	}
+
+	hovered, _ := l.findComponentAtPoint(image.Point{X: x, Y: y})
+	if hovered != nil && hovered.Type == config.TypeTextBox {
+		hovered.PushCommand(&data.Command{Type: textbox.CommandScroll, Value: shift})
+	}
+}
+
+func (l *Layout) HandleKeyboardEvent(e string) {
+
+	if l.mode == ModeComponentAdd {
+		l.handleWizardEvent(e)
+		return
+	}
+
+	action := l.keys.GetAction(e)
+
+	if l.isOverlayMode() {
+		l.handleOverlayEvent(action)
+		return
+	}
+
+	if b, ok := l.findBinding(action); ok && b.handle != nil {
+		b.handle(l, action)
+	}
+}
+
+// openOverlay shows help or diagnostics over the components, keeping the mode to return to
+func (l *Layout) openOverlay(m Mode) {
+	if !l.isOverlayMode() {
+		l.overlayReturnMode = l.mode
+	}
+	l.help.Open(l.Components)
+	l.diagnostics.Open(l.Components)
+	l.changeMode(m)
+}
+
+// handleOverlayEvent switches between the overlays, or closes the current one
+func (l *Layout) handleOverlayEvent(action console.Action) {
+	switch {
+	case action == console.ActionHelp && l.mode != ModeHelp:
+		l.openOverlay(ModeHelp)
+	case action == console.ActionStats && l.mode != ModeDiagnostics:
+		l.openOverlay(ModeDiagnostics)
+	case action == console.ActionHelp || action == console.ActionStats || action == console.ActionReset:
+		l.closeOverlay()
+	}
+}
+
+// closeOverlay returns to the mode, which the overlay was opened in
+func (l *Layout) closeOverlay() {
+	l.changeMode(l.overlayReturnMode)
+}
+
+func (l *Layout) isOverlayMode() bool {
+	return l.mode == ModeHelp || l.mode == ModeDiagnostics
+}
+
+// handleWizardEvent passes keys to the wizard, and emits the draft, when it's completed
+func (l *Layout) handleWizardEvent(e string) {
+	if e == console.KeyEsc {
+		l.changeMode(ModeDefault)
+		return
+	}
+	if l.wizard.HandleKeyboardEvent(e) {
+		l.AddComponentEvents <- l.wizard.GetDraft()
+	}
+}
+
+// CompleteComponentAdd closes the wizard and selects the new component, which was added to the end
+// of the components. The wizard is kept open with the error, if the component can't be created
+func (l *Layout) CompleteComponentAdd(err error) {
+
+	if err != nil {
+		l.wizard.SetError(err)
+		return
+	}
+
+	l.selection = len(l.Components) - 1
+	l.positionsChanged = true
+	l.retile()
+	l.changeMode(ModeDefault)
+}
+
+// CompleteComponentDuplicate selects the copy, which was added to the end of the components
+func (l *Layout) CompleteComponentDuplicate() {
+	l.selection = len(l.Components) - 1
+	l.positionsChanged = true
+	l.retile()
+}
+
+func (l *Layout) RemoveComponent(cpt *component.Component) {
+	for i, c := range l.Components {
+		if c == cpt {
+			l.Components = append(l.Components[:i], l.Components[i+1:]...)
 			break
 		}
-	case console.KeyEsc:
-		l.resetAlerts()
-		switch l.mode {
-		case ModeChartPinpoint:
-			selected.CommandChannel <- &data.Command{Type: runchart.CommandDisableSelection}
-			fallthrough
-		case ModeComponentSelect:
-			fallthrough
-		case ModeMenuOptionSelect:
-			l.menu.Idle()
-			l.changeMode(ModeDefault)
-		case ModeComponentMove:
-			fallthrough
-		case ModeComponentResize:
-			l.menu.Idle()
-			l.changeMode(ModeDefault)
-		}
-	case console.KeyLeft:
-		switch l.mode {
-		case ModeDefault:
-			l.changeMode(ModeComponentSelect)
-			l.menu.Highlight(l.getComponent(l.selection))
-		case ModeChartPinpoint:
-			selected.CommandChannel <- &data.Command{Type: runchart.CommandMoveSelection, Value: -1}
-		case ModeComponentSelect:
-			l.moveSelection(e)
-			l.menu.Highlight(l.getComponent(l.selection))
-		case ModeComponentMove:
-			selected.Move(-1, 0)
-		case ModeComponentResize:
-			selected.Resize(-1, 0)
-		}
-	case console.KeyRight:
-		switch l.mode {
-		case ModeDefault:
-			l.changeMode(ModeComponentSelect)
-			l.menu.Highlight(l.getComponent(l.selection))
-		case ModeChartPinpoint:
-			selected.CommandChannel <- &data.Command{Type: runchart.CommandMoveSelection, Value: 1}
-		case ModeComponentSelect:
-			l.moveSelection(e)
-			l.menu.Highlight(l.getComponent(l.selection))
-		case ModeComponentMove:
-			selected.Move(1, 0)
-		case ModeComponentResize:
-			selected.Resize(1, 0)
-		}
-	case console.KeyUp:
-		switch l.mode {
-		case ModeDefault:
-			l.changeMode(ModeComponentSelect)
-			l.menu.Highlight(l.getComponent(l.selection))
-		case ModeComponentSelect:
-			l.moveSelection(e)
-			l.menu.Highlight(l.getComponent(l.selection))
-		case ModeMenuOptionSelect:
-			l.menu.Up()
-		case ModeComponentMove:
-			selected.Move(0, -1)
-		case ModeComponentResize:
-			selected.Resize(0, -1)
-		}
-	case console.KeyDown:
-		switch l.mode {
-		case ModeDefault:
-			l.changeMode(ModeComponentSelect)
-			l.menu.Highlight(l.getComponent(l.selection))
-		case ModeComponentSelect:
-			l.moveSelection(e)
-			l.menu.Highlight(l.getComponent(l.selection))
-		case ModeMenuOptionSelect:
-			l.menu.Down()
-		case ModeComponentMove:
-			selected.Move(0, 1)
-		case ModeComponentResize:
-			selected.Resize(0, 1)
-		}
 	}
+	if l.hovered == cpt {
+		l.hovered = nil
+	}
+	l.selection = 0
+	l.positionsChanged = true
+	l.retile()
+}
+
+// tile splits the grid between the components, so none of them overlap
+func (l *Layout) tile() {
+
+	weights := make([]int, len(l.Components))
+	for i, c := range l.Components {
+		weights[i] = c.Weight
+	}
+
+	for i, r := range config.Tile(weights, l.grid, l.tiling) {
+		c := l.Components[i]
+		c.Location = config.Location{X: r.Min.X, Y: r.Min.Y}
+		c.Size = config.Size{X: r.Dx(), Y: r.Dy()}
+	}
+
+	l.positionsChanged = true
+}
+
+// retile keeps the components tiled in auto layout, when they are added or removed
+func (l *Layout) retile() {
+	if l.auto {
+		l.tile()
+	}
+}
+
+// getOverlapWarning names the first pair of the overlapping components, if there are any
+func (l *Layout) getOverlapWarning() string {
+
+	rectangles := make([]image.Rectangle, len(l.Components))
+	for i, c := range l.Components {
+		rectangles[i] = image.Rect(c.Location.X, c.Location.Y, c.Location.X+c.Size.X, c.Location.Y+c.Size.Y)
+	}
+
+	overlaps := config.GetOverlaps(rectangles)
+	if len(overlaps) == 0 {
+		return ""
+	}
+
+	first := overlaps[0]
+	warning := fmt.Sprintf("'%s' overlaps with '%s'", l.Components[first[0]].Title, l.Components[first[1]].Title)
+	if len(overlaps) > 1 {
+		warning += fmt.Sprintf(" (+%d more)", len(overlaps)-1)
+	}
+
+	return warning
+}
+
+// IsInputMode returns true, when keys are used for text input, rather than as shortcuts
+func (l *Layout) IsInputMode() bool {
+	return l.mode == ModeComponentAdd
 }
 
+// ChangeDimensions switches components to the positions of the breakpoint, which fits the new width
 func (l *Layout) ChangeDimensions(width, height int) {
+
 	l.SetRect(0, 0, width, height)
+
+	if breakpoint := config.GetBreakpoint(l.breakpoints, width); breakpoint != l.breakpoint {
+		l.breakpoint = breakpoint
+		for _, c := range l.Components {
+			c.SetBreakpoint(breakpoint)
+		}
+	}
 }
 
 func (l *Layout) getComponent(i int) *component.Component {
@@ -232,7 +427,7 @@ func (l *Layout) getSelection() *component.Component {
 	return l.Components[l.selection]
 }
 
-func (l *Layout) moveSelection(direction string) {
+func (l *Layout) moveSelection(direction console.Action) {
 
 	previouslySelected := l.getSelection()
 	newlySelectedIndex := l.selection + 1
@@ -252,36 +447,36 @@ func (l *Layout) moveSelection(direction string) {
 		var currentCornerPoint image.Point
 
 		switch direction {
-		case console.KeyLeft:
+		case console.ActionLeft:
 			previouslySelectedCornerPoint = util.GetRectLeftSideCenter(previouslySelected.GetRect())
 			newlySelectedCornerPoint = util.GetRectRightSideCenter(l.getComponent(newlySelectedIndex).GetRect())
 			currentCornerPoint = util.GetRectRightSideCenter(current.GetRect())
-		case console.KeyRight:
+		case console.ActionRight:
 			previouslySelectedCornerPoint = util.GetRectRightSideCenter(previouslySelected.GetRect())
 			newlySelectedCornerPoint = util.GetRectLeftSideCenter(l.getComponent(newlySelectedIndex).GetRect())
 			currentCornerPoint = util.GetRectLeftSideCenter(current.GetRect())
-		case console.KeyUp:
+		case console.ActionUp:
 			previouslySelectedCornerPoint = util.GetRectTopSideCenter(previouslySelected.GetRect())
 			newlySelectedCornerPoint = util.GetRectBottomSideCenter(l.getComponent(newlySelectedIndex).GetRect())
 			currentCornerPoint = util.GetRectBottomSideCenter(current.GetRect())
-		case console.KeyDown:
+		case console.ActionDown:
 			previouslySelectedCornerPoint = util.GetRectBottomSideCenter(previouslySelected.GetRect())
 			newlySelectedCornerPoint = util.GetRectTopSideCenter(l.getComponent(newlySelectedIndex).GetRect())
 			currentCornerPoint = util.GetRectTopSideCenter(current.GetRect())
 		}
 
 		switch direction {
-		case console.KeyLeft:
+		case console.ActionLeft:
 			fallthrough
-		case console.KeyRight:
+		case console.ActionRight:
 			if ui.AbsInt(currentCornerPoint.X-previouslySelectedCornerPoint.X) <= ui.AbsInt(newlySelectedCornerPoint.X-previouslySelectedCornerPoint.X) {
 				if ui.AbsInt(currentCornerPoint.Y-previouslySelectedCornerPoint.Y) <= ui.AbsInt(newlySelectedCornerPoint.Y-previouslySelectedCornerPoint.Y) {
 					newlySelectedIndex = i
 				}
 			}
-		case console.KeyUp:
+		case console.ActionUp:
 			fallthrough
-		case console.KeyDown:
+		case console.ActionDown:
 			if ui.AbsInt(currentCornerPoint.Y-previouslySelectedCornerPoint.Y) <= ui.AbsInt(newlySelectedCornerPoint.Y-previouslySelectedCornerPoint.Y) {
 				if ui.AbsInt(currentCornerPoint.X-previouslySelectedCornerPoint.X) <= ui.AbsInt(newlySelectedCornerPoint.X-previouslySelectedCornerPoint.X) {
 					newlySelectedIndex = i
@@ -297,8 +492,7 @@ func (l *Layout) moveSelection(direction string) {
 
 func (l *Layout) Draw(buffer *ui.Buffer) {
 
-	columnWidth := float64(l.GetRect().Dx()) / float64(console.ColumnsCount)
-	rowHeight := float64(l.GetRect().Dy()-statusbarHeight) / float64(console.RowsCount)
+	columnWidth, rowHeight := l.getCellSize()
 
 	for _, c := range l.Components {
 		rectangle := calculateComponentCoordinates(c, columnWidth, rowHeight)
@@ -309,18 +503,33 @@ func (l *Layout) Draw(buffer *ui.Buffer) {
 		c.Draw(buffer)
 	}
 
+	l.statusbar.SetWarning(l.getOverlapWarning())
 	l.statusbar.SetRect(
 		0, l.GetRect().Dy()-statusbarHeight,
 		l.GetRect().Dx(), l.GetRect().Dy())
 
 	l.statusbar.Draw(buffer)
 	l.menu.Draw(buffer)
+
+	if l.mode == ModeComponentAdd {
+		l.wizard.SetArea(l.GetRect())
+		l.wizard.Draw(buffer)
+	}
+
+	if l.mode == ModeHelp {
+		l.help.SetArea(l.GetRect())
+		l.help.Draw(buffer)
+	}
+
+	if l.mode == ModeDiagnostics {
+		l.diagnostics.SetArea(l.GetRect())
+		l.diagnostics.Draw(buffer)
+	}
 }
 
 func (l *Layout) findComponentAtPoint(point image.Point) (*component.Component, int) {
 
-	columnWidth := float64(l.GetRect().Dx()) / float64(console.ColumnsCount)
-	rowHeight := float64(l.GetRect().Dy()-statusbarHeight) / float64(console.RowsCount)
+	columnWidth, rowHeight := l.getCellSize()
 
 	for i, c := range l.Components {
 
@@ -334,6 +543,13 @@ func (l *Layout) findComponentAtPoint(point image.Point) (*component.Component,
 	return nil, -1
 }
 
+// getCellSize returns the screen size of the grid cell
+func (l *Layout) getCellSize() (float64, float64) {
+	columnWidth := float64(l.GetRect().Dx()) / float64(l.grid.Columns)
+	rowHeight := float64(l.GetRect().Dy()-statusbarHeight) / float64(l.grid.Rows)
+	return columnWidth, rowHeight
+}
+
 func calculateComponentCoordinates(c *component.Component, columnWidth float64, rowHeight float64) image.Rectangle {
 
 	x1 := math.Floor(float64(c.Location.X) * columnWidth)
@@ -357,7 +573,7 @@ func calculateComponentCoordinates(c *component.Component, columnWidth float64,
 
 func (l *Layout) resetAlerts() {
 	for _, c := range l.Components {
-		c.AlertChannel <- nil
+		c.ResetAlert()
 	}
 }
 
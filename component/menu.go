@@ -1,6 +1,7 @@
 package component
 
 import (
+	"fmt"
 	ui "github.com/gizak/termui/v3"
 	"github.com/sqshq/sampler/component/util"
 	"github.com/sqshq/sampler/config"
@@ -10,11 +11,13 @@ import (
 
 type Menu struct {
 	*ui.Block
-	options   []menuOption
+	options   []MenuOption
 	component Component
 	mode      menuMode
-	option    menuOption
+	option    MenuOption
 	palette   console.Palette
+	deletable bool
+	keys      console.KeyMap
 }
 
 type menuMode rune
@@ -24,32 +27,40 @@ const (
 	menuModeHighlight     menuMode = 1
 	menuModeOptionSelect  menuMode = 2
 	menuModeMoveAndResize menuMode = 3
+	menuModeConfirmDelete menuMode = 4
 )
 
-type menuOption string
+// MenuOption is applied to the selected component. Options are described in the help along with the keys
+type MenuOption string
 
 const (
-	MenuOptionMove     menuOption = "MOVE"
-	MenuOptionResize   menuOption = "RESIZE"
-	MenuOptionPinpoint menuOption = "PINPOINT"
-	MenuOptionResume   menuOption = "RESUME"
+	MenuOptionMove      MenuOption = "MOVE"
+	MenuOptionResize    MenuOption = "RESIZE"
+	MenuOptionPinpoint  MenuOption = "PINPOINT"
+	MenuOptionPause     MenuOption = "PAUSE SAMPLING"
+	MenuOptionRefresh   MenuOption = "REFRESH NOW"
+	MenuOptionDuplicate MenuOption = "DUPLICATE"
+	MenuOptionDelete    MenuOption = "DELETE"
+	MenuOptionTile      MenuOption = "TILE"
+	MenuOptionResume    MenuOption = "RESUME"
 )
 
 const (
 	minimalMenuHeight = 8
 )
 
-func NewMenu(palette console.Palette) *Menu {
+func NewMenu(palette console.Palette, keys console.KeyMap) *Menu {
 	return &Menu{
 		Block:   NewBlock("", true, palette),
-		options: []menuOption{MenuOptionMove, MenuOptionResize, MenuOptionPinpoint, MenuOptionResume},
+		options: []MenuOption{MenuOptionMove, MenuOptionResize, MenuOptionPinpoint, MenuOptionPause, MenuOptionRefresh, MenuOptionDuplicate, MenuOptionDelete, MenuOptionTile, MenuOptionResume},
 		mode:    menuModeIdle,
 		option:  MenuOptionMove,
 		palette: palette,
+		keys:    keys,
 	}
 }
 
-func (m *Menu) GetSelectedOption() menuOption {
+func (m *Menu) GetSelectedOption() MenuOption {
 	return m.option
 }
 
@@ -62,6 +73,9 @@ func (m *Menu) Highlight(component *Component) {
 
 func (m *Menu) Choose() {
 	m.mode = menuModeOptionSelect
+	if !m.isAvailable(m.option) {
+		m.option = MenuOptionMove
+	}
 }
 
 func (m *Menu) Idle() {
@@ -75,7 +89,7 @@ func (m *Menu) Up() {
 			break
 		}
 	}
-	if m.option == MenuOptionPinpoint && m.component.Type != config.TypeRunChart {
+	if !m.isAvailable(m.option) {
 		m.Up()
 	}
 }
@@ -87,15 +101,42 @@ func (m *Menu) Down() {
 			break
 		}
 	}
-	if m.option == MenuOptionPinpoint && m.component.Type != config.TypeRunChart {
+	if !m.isAvailable(m.option) {
 		m.Down()
 	}
 }
 
+// isAvailable returns false for options, which are not applicable to the component: pinpoint is
+// supported by runcharts only, and components with push source can't be duplicated, since the source
+// is listened by one component only. Push source can't be refreshed either, since there is nothing to sample
+func (m *Menu) isAvailable(option MenuOption) bool {
+	switch option {
+	case MenuOptionPinpoint:
+		return m.component.Type == config.TypeRunChart
+	case MenuOptionDuplicate, MenuOptionRefresh:
+		return m.component.Source == nil
+	}
+	return true
+}
+
+// getLabel returns the option name, which depends on the component state for pause
+func (m *Menu) getLabel(option MenuOption) string {
+	if option == MenuOptionPause && m.component.Paused {
+		return "RESUME SAMPLING"
+	}
+	return string(option)
+}
+
 func (m *Menu) MoveOrResize() {
 	m.mode = menuModeMoveAndResize
 }
 
+// ConfirmDelete asks for the deletion confirmation. The only component can't be deleted
+func (m *Menu) ConfirmDelete(deletable bool) {
+	m.mode = menuModeConfirmDelete
+	m.deletable = deletable
+}
+
 func (m *Menu) Draw(buffer *ui.Buffer) {
 
 	if m.mode == menuModeIdle {
@@ -118,13 +159,15 @@ func (m *Menu) Draw(buffer *ui.Buffer) {
 		m.renderMoveAndResize(buffer)
 	case menuModeOptionSelect:
 		m.renderOptions(buffer)
+	case menuModeConfirmDelete:
+		m.renderConfirmDelete(buffer)
 	}
 }
 
 func (m *Menu) renderHighlight(buffer *ui.Buffer) {
 
-	optionsText := "<ENTER> to view options"
-	resumeText := "<ESC> to resume"
+	optionsText := fmt.Sprintf("<%s> to view options", m.keys.GetHint(console.ActionSelect))
+	resumeText := fmt.Sprintf("<%s> to resume", m.keys.GetHint(console.ActionReset))
 
 	if m.Dy() <= minimalMenuHeight {
 		buffer.SetString(
@@ -158,7 +201,7 @@ func (m *Menu) renderHighlight(buffer *ui.Buffer) {
 
 func (m *Menu) renderMoveAndResize(buffer *ui.Buffer) {
 
-	saveText := "<ENTER> to save changes"
+	saveText := fmt.Sprintf("<%s> to save changes", m.keys.GetHint(console.ActionSelect))
 
 	if m.Dy() <= minimalMenuHeight {
 		buffer.SetString(saveText, ui.NewStyle(console.ColorDarkGrey), util.GetMiddlePoint(m.Block.Rectangle, saveText, -1))
@@ -169,6 +212,25 @@ func (m *Menu) renderMoveAndResize(buffer *ui.Buffer) {
 	buffer.SetString(saveText, ui.NewStyle(console.ColorDarkGrey), util.GetMiddlePoint(m.Block.Rectangle, saveText, 3))
 }
 
+func (m *Menu) renderConfirmDelete(buffer *ui.Buffer) {
+
+	questionText := "Delete the component?"
+	cancelText := fmt.Sprintf("<%s> to cancel", m.keys.GetHint(console.ActionReset))
+	confirmText := fmt.Sprintf("<%s> to delete, %s", m.keys.GetHint(console.ActionSelect), cancelText)
+
+	if !m.deletable {
+		questionText = "The only component can't be deleted"
+		confirmText = cancelText
+	}
+
+	buffer.SetString(questionText, ui.NewStyle(m.palette.BaseColor), util.GetMiddlePoint(m.Block.Rectangle, questionText, -1))
+
+	confirmTextPoint := util.GetMiddlePoint(m.Block.Rectangle, confirmText, 1)
+	if confirmTextPoint.Y+1 < m.Inner.Max.Y {
+		buffer.SetString(confirmText, ui.NewStyle(console.ColorDarkGrey), confirmTextPoint)
+	}
+}
+
 func (m *Menu) printAllDirectionsArrowSign(buffer *ui.Buffer, y int) {
 
 	arrows := []string{
@@ -192,19 +254,29 @@ func (m *Menu) renderOptions(buffer *ui.Buffer) {
 	highlightedStyle := ui.NewStyle(m.palette.ReverseColor, console.ColorOlive)
 	regularStyle := ui.NewStyle(m.palette.BaseColor, m.palette.ReverseColor)
 
-	offset := 1
+	var options []MenuOption
 	for _, option := range m.options {
+		if m.isAvailable(option) {
+			options = append(options, option)
+		}
+	}
+
+	// options are spaced by an empty line, if there is enough room
+	spacing := 2
+	if spacing*len(options) > m.Inner.Dy() {
+		spacing = 1
+	}
+
+	for i, option := range options {
 
 		style := regularStyle
 		if m.option == option {
 			style = highlightedStyle
 		}
 
-		if option != MenuOptionPinpoint || m.component.Type == config.TypeRunChart {
-			offset += 2
-			point := util.GetMiddlePoint(m.Block.Rectangle, string(option), offset-6)
-			buffer.SetString(string(option), style, point)
-		}
+		label := m.getLabel(option)
+		point := util.GetMiddlePoint(m.Block.Rectangle, label, spacing*i-spacing*(len(options)-1)/2)
+		buffer.SetString(label, style, point)
 	}
 }
 
@@ -215,8 +287,8 @@ func (m *Menu) updateDimensions() {
 
 func (m *Menu) drawInnerBorder(buffer *ui.Buffer) {
 
-	verticalCell := ui.Cell{ui.VERTICAL_LINE, m.BorderStyle}
-	horizontalCell := ui.Cell{ui.HORIZONTAL_LINE, m.BorderStyle}
+	verticalCell := ui.Cell{Rune: ui.VERTICAL_LINE, Style: m.BorderStyle}
+	horizontalCell := ui.Cell{Rune: ui.HORIZONTAL_LINE, Style: m.BorderStyle}
 
 	// draw lines
 	buffer.Fill(horizontalCell, image.Rect(m.Min.X+2, m.Min.Y+2, m.Max.X-2, m.Min.Y))
@@ -225,8 +297,8 @@ func (m *Menu) drawInnerBorder(buffer *ui.Buffer) {
 	buffer.Fill(verticalCell, image.Rect(m.Max.X-2, m.Min.Y, m.Max.X-3, m.Max.Y))
 
 	// draw corners
-	buffer.SetCell(ui.Cell{ui.TOP_LEFT, m.BorderStyle}, image.Pt(m.Min.X+2, m.Min.Y+1))
-	buffer.SetCell(ui.Cell{ui.TOP_RIGHT, m.BorderStyle}, image.Pt(m.Max.X-3, m.Min.Y+1))
-	buffer.SetCell(ui.Cell{ui.BOTTOM_LEFT, m.BorderStyle}, image.Pt(m.Min.X+2, m.Max.Y-2))
-	buffer.SetCell(ui.Cell{ui.BOTTOM_RIGHT, m.BorderStyle}, image.Pt(m.Max.X-3, m.Max.Y-2))
+	buffer.SetCell(ui.Cell{Rune: ui.TOP_LEFT, Style: m.BorderStyle}, image.Pt(m.Min.X+2, m.Min.Y+1))
+	buffer.SetCell(ui.Cell{Rune: ui.TOP_RIGHT, Style: m.BorderStyle}, image.Pt(m.Max.X-3, m.Min.Y+1))
+	buffer.SetCell(ui.Cell{Rune: ui.BOTTOM_LEFT, Style: m.BorderStyle}, image.Pt(m.Min.X+2, m.Max.Y-2))
+	buffer.SetCell(ui.Cell{Rune: ui.BOTTOM_RIGHT, Style: m.BorderStyle}, image.Pt(m.Max.X-3, m.Max.Y-2))
 }
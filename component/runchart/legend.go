@@ -57,7 +57,7 @@ func (c *RunChart) renderLegend(buffer *ui.Buffer, rectangle image.Rectangle) {
 			titleStyle := ui.NewStyle(line.color)
 			detailsStyle := ui.NewStyle(c.palette.BaseColor)
 
-			buffer.SetString(string(ui.DOT), titleStyle, image.Pt(x-2, y))
+			buffer.SetString(string(line.symbol), titleStyle, image.Pt(x-2, y))
 			buffer.SetString(line.label, titleStyle, image.Pt(x, y))
 
 			if c.mode == ModePinpoint {
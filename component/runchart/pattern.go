@@ -0,0 +1,72 @@
+package runchart
+
+import (
+	ui "github.com/gizak/termui/v3"
+	"github.com/sqshq/sampler/config"
+	"image"
+)
+
+// markerSpacing is the min distance in cells between the markers of the same line
+const markerSpacing = 4
+
+// linePatterns lists braille dots, which are on and off in turn along the line
+var linePatterns = map[config.LineStyle][]bool{
+	config.LineDashed: {true, true, true, true, false, false, false},
+	config.LineDotted: {true, false, false},
+}
+
+// legendSymbols show the line pattern in the legend, if the line has no marker
+var legendSymbols = map[config.LineStyle]rune{
+	config.LineSolid:  ui.DOT,
+	config.LineDashed: '╌',
+	config.LineDotted: '┄',
+}
+
+// setPatternLine draws the line dot by dot, skipping the dots, which are off in the pattern.
+// The first point is drawn only when the line starts, and the step is kept between
+// the segments, so the pattern continues along the whole line
+func setPatternLine(canvas *ui.Canvas, from image.Point, to image.Point, color ui.Color, pattern []bool, step *int) {
+
+	points := getLinePoints(from, to)
+	if *step == 0 {
+		points = append([]image.Point{from}, points...)
+	}
+
+	for _, point := range points {
+		if pattern[*step%len(pattern)] {
+			canvas.SetPoint(point, color)
+		}
+		*step++
+	}
+}
+
+// getLinePoints returns the points of the line, excluding the first one, using Bresenham's algorithm
+func getLinePoints(from image.Point, to image.Point) []image.Point {
+
+	dx, dy := ui.AbsInt(to.X-from.X), -ui.AbsInt(to.Y-from.Y)
+	sx, sy := getDirection(from.X, to.X), getDirection(from.Y, to.Y)
+	e := dx + dy
+
+	var points []image.Point
+	for point := from; point != to; {
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			point.X += sx
+		}
+		if e2 <= dx {
+			e += dx
+			point.Y += sy
+		}
+		points = append(points, point)
+	}
+
+	return points
+}
+
+func getDirection(from int, to int) int {
+	if from < to {
+		return 1
+	}
+	return -1
+}
@@ -0,0 +1,53 @@
+package runchart
+
+import (
+	ui "github.com/gizak/termui/v3"
+	"github.com/sqshq/sampler/config"
+	"image"
+	"reflect"
+	"testing"
+)
+
+func TestGetLinePoints(t *testing.T) {
+	tests := []struct {
+		name string
+		from image.Point
+		to   image.Point
+		want []image.Point
+	}{
+		{"should return nothing for a single point", image.Pt(1, 1), image.Pt(1, 1), nil},
+		{"should return horizontal line", image.Pt(0, 0), image.Pt(3, 0), []image.Point{{1, 0}, {2, 0}, {3, 0}}},
+		{"should return diagonal line", image.Pt(2, 2), image.Pt(0, 0), []image.Point{{1, 1}, {0, 0}}},
+		{"should return steep line", image.Pt(0, 0), image.Pt(1, 3), []image.Point{{0, 1}, {1, 2}, {1, 3}}},
+	}
+
+	for _, test := range tests {
+		if got := getLinePoints(test.from, test.to); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: getLinePoints(%v, %v) = %v, want %v", test.name, test.from, test.to, got, test.want)
+		}
+	}
+}
+
+func TestSetPatternLine(t *testing.T) {
+
+	canvas := ui.NewCanvas()
+	canvas.Rectangle = image.Rect(0, 0, 10, 1)
+	pattern := linePatterns[config.LineDotted]
+
+	// the pattern continues from the first segment to the second one
+	step := 0
+	setPatternLine(canvas, image.Pt(0, 0), image.Pt(4, 0), ui.ColorWhite, pattern, &step)
+	setPatternLine(canvas, image.Pt(4, 0), image.Pt(9, 0), ui.ColorWhite, pattern, &step)
+
+	var got []int
+	for x := 0; x < 10; x++ {
+		cell := canvas.Canvas.GetCells()[image.Pt(x/2, 0)]
+		if cell.Rune&(0x01<<uint(x%2*3)) != 0 {
+			got = append(got, x)
+		}
+	}
+
+	if want := []int{0, 3, 6, 9}; !reflect.DeepEqual(got, want) {
+		t.Errorf("setPatternLine() dots = %v, want %v", got, want)
+	}
+}
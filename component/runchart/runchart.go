@@ -36,6 +36,7 @@ const (
 const (
 	CommandDisableSelection = "DISABLE_SELECTION"
 	CommandMoveSelection    = "MOVE_SELECTION"
+	CommandPinpointAt       = "PINPOINT_AT"
 )
 
 // RunChart displays observed data in a time sequence
@@ -64,6 +65,9 @@ type TimeLine struct {
 	extrema             ValueExtrema
 	color               ui.Color
 	label               string
+	pattern             []bool
+	marker              rune
+	symbol              rune
 	selectionCoordinate int
 	selectionPoint      TimePoint
 }
@@ -93,7 +97,11 @@ func NewRunChart(c config.RunChartConfig, palette console.Palette) *RunChart {
 	}
 
 	for _, i := range c.Items {
-		chart.AddLine(*i.Label, *i.Color)
+		var marker rune
+		if i.Marker != nil {
+			marker = []rune(*i.Marker)[0]
+		}
+		chart.AddLine(*i.Label, *i.Color, *i.Line, marker)
 	}
 
 	go func() {
@@ -102,14 +110,19 @@ func NewRunChart(c config.RunChartConfig, palette console.Palette) *RunChart {
 			case sample := <-chart.SampleChannel:
 				chart.consumeSample(sample)
 			case alert := <-chart.AlertChannel:
-				chart.Alert = alert
+				chart.SetAlert(alert)
 			case command := <-chart.CommandChannel:
+				// selection is rendered by the UI goroutine
+				chart.mutex.Lock()
 				switch command.Type {
 				case CommandDisableSelection:
 					chart.disableSelection()
 				case CommandMoveSelection:
 					chart.moveSelection(command.Value.(int))
+				case CommandPinpointAt:
+					chart.pinpointAt(command.Value.(int))
 				}
+				chart.mutex.Unlock()
 			}
 		}
 	}()
@@ -140,15 +153,25 @@ func (c *RunChart) Draw(buffer *ui.Buffer) {
 	c.renderAxes(buffer)
 	c.renderLines(buffer, drawArea)
 	c.renderLegend(buffer, drawArea)
-	component.RenderAlert(c.Alert, c.Rectangle, buffer)
+	component.RenderAlert(c.GetAlert(), c.Rectangle, buffer)
 	c.mutex.Unlock()
 }
 
-func (c *RunChart) AddLine(Label string, color ui.Color) {
+// AddLine adds a line with the pattern and the marker, which tell it apart without color. Zero marker is not drawn
+func (c *RunChart) AddLine(Label string, color ui.Color, style config.LineStyle, marker rune) {
+
+	symbol := legendSymbols[style]
+	if marker != 0 {
+		symbol = marker
+	}
+
 	line := TimeLine{
 		points:  []TimePoint{},
 		color:   color,
 		label:   Label,
+		pattern: linePatterns[style],
+		marker:  marker,
+		symbol:  symbol,
 		extrema: ValueExtrema{max: -math.MaxFloat64, min: math.MaxFloat64},
 	}
 	c.lines = append(c.lines, line)
@@ -203,6 +226,7 @@ func (c *RunChart) renderLines(buffer *ui.Buffer, drawArea image.Rectangle) {
 
 	selectionCoordinate := c.calculateTimeCoordinate(c.selection)
 	selectionPoints := make(map[int]image.Point)
+	markers := make(map[image.Point]TimeLine)
 
 	probe := c.lines[0].points[0]
 	probeCalculatedCoordinate := c.calculateTimeCoordinate(probe.time)
@@ -244,7 +268,7 @@ func (c *RunChart) renderLines(buffer *ui.Buffer, drawArea image.Rectangle) {
 
 			if line.selectionCoordinate == 0 {
 				// instantiate selection coordinate as the closest point to the cursor time
-				if len(line.points) > j+1 && ui.AbsInt(timePoint.coordinate-selectionCoordinate) > ui.AbsInt(line.points[j+1].coordinate-selectionCoordinate) {
+				if selected, ok := selectionPoints[i]; !ok || ui.AbsInt(point.X-selectionCoordinate) < ui.AbsInt(selected.X-selectionCoordinate) {
 					selectionPoints[i] = point
 					c.lines[i].selectionPoint = timePoint
 				}
@@ -256,6 +280,8 @@ func (c *RunChart) renderLines(buffer *ui.Buffer, drawArea image.Rectangle) {
 			xOrder = append(xOrder, point.X)
 		}
 
+		step := 0
+
 		for i, x := range xOrder {
 
 			currentPoint := xPoint[x]
@@ -267,16 +293,34 @@ func (c *RunChart) renderLines(buffer *ui.Buffer, drawArea image.Rectangle) {
 				previousPoint = xPoint[xOrder[i-1]]
 			}
 
-			canvas.SetLine(
-				braillePoint(previousPoint),
-				braillePoint(currentPoint),
-				line.color,
-			)
+			if line.pattern == nil {
+				canvas.SetLine(
+					braillePoint(previousPoint),
+					braillePoint(currentPoint),
+					line.color,
+				)
+			} else {
+				setPatternLine(canvas, braillePoint(previousPoint), braillePoint(currentPoint), line.color, line.pattern, &step)
+			}
+		}
+
+		// markers are placed from the latest point, so they keep the distance from the chart edge
+		if line.marker != 0 {
+			for i, last := len(xOrder)-1, 0; i >= 0; i-- {
+				if point := xPoint[xOrder[i]]; i == len(xOrder)-1 || last-point.X >= markerSpacing {
+					markers[point] = line
+					last = point.X
+				}
+			}
 		}
 	}
 
 	canvas.Draw(buffer)
 
+	for point, line := range markers {
+		buffer.SetCell(ui.NewCell(line.marker, ui.NewStyle(line.color)), point)
+	}
+
 	if c.mode == ModePinpoint {
 		for lineIndex, point := range selectionPoints {
 			buffer.SetCell(ui.NewCell(console.SymbolSelection, ui.NewStyle(c.lines[lineIndex].color)), point)
@@ -321,7 +365,23 @@ func (c *RunChart) moveSelection(shift int) {
 		return
 	}
 
-	c.selection = c.selection.Add(c.grid.timePerPoint * time.Duration(shift))
+	c.setSelection(c.selection.Add(c.grid.timePerPoint * time.Duration(shift)))
+}
+
+// pinpointAt selects the time, which corresponds to the x coordinate on the screen, e.g. under the mouse pointer
+func (c *RunChart) pinpointAt(x int) {
+
+	if c.mode == ModeDefault {
+		c.mode = ModePinpoint
+	}
+
+	c.setSelection(c.grid.timeRange.max.Add(-c.grid.timePerPoint * time.Duration(c.grid.maxTimeWidth-x)))
+}
+
+// setSelection keeps the selected time within the grid range
+func (c *RunChart) setSelection(t time.Time) {
+
+	c.selection = t
 	if c.selection.After(c.grid.timeRange.max) {
 		c.selection = c.grid.timeRange.max
 	} else if c.selection.Before(c.grid.timeRange.min) {
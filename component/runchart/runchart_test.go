@@ -0,0 +1,54 @@
+package runchart
+
+import (
+	ui "github.com/gizak/termui/v3"
+	"github.com/sqshq/sampler/config"
+	"github.com/sqshq/sampler/console"
+	"github.com/sqshq/sampler/data"
+	"image"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestRunChart_concurrentResize(t *testing.T) {
+
+	label, color, line, rate, scale := "value", ui.Color(1), config.LineSolid, 10, 1
+	chart := NewRunChart(config.RunChartConfig{
+		ComponentConfig: config.ComponentConfig{Title: "test", RateMs: &rate},
+		Legend:          &config.LegendConfig{Enabled: true, Details: true},
+		Scale:           &scale,
+		Items:           []config.Item{{Label: &label, Color: &color, Line: &line}},
+	}, console.GetPalette(console.ThemeDark))
+
+	// sampler pushes samples and alerts, while the UI goroutine resizes, redraws,
+	// resets alerts and moves the selection, as it's done on a terminal resize or in pinpoint mode
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 500; i++ {
+			chart.PushSample(&data.Sample{Label: label, Value: strconv.Itoa(i % 7), Color: &color})
+			if i%50 == 0 {
+				chart.PushAlert(&data.Alert{Title: "test", Recoverable: true})
+			}
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			chart.SetRect(0, 0, 40+i%20, 10+i%10)
+			buffer := ui.NewBuffer(image.Rect(0, 0, 60, 20))
+			chart.Draw(buffer)
+			chart.ResetAlert()
+			chart.PushCommand(&data.Command{Type: CommandMoveSelection, Value: 1})
+			if i%10 == 0 {
+				chart.PushCommand(&data.Command{Type: CommandDisableSelection})
+			}
+		}
+	}()
+
+	wg.Wait()
+}
@@ -42,7 +42,7 @@ func NewSparkLine(c config.SparkLineConfig, palette console.Palette) *SparkLine
 			case sample := <-line.SampleChannel:
 				line.consumeSample(sample)
 			case alert := <-line.AlertChannel:
-				line.Alert = alert
+				line.SetAlert(alert)
 			}
 		}
 	}()
@@ -140,5 +140,5 @@ func (s *SparkLine) Draw(buffer *ui.Buffer) {
 	s.mutex.Unlock()
 
 	s.Block.Draw(buffer)
-	component.RenderAlert(s.Alert, s.Rectangle, buffer)
+	component.RenderAlert(s.GetAlert(), s.Rectangle, buffer)
 }
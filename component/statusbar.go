@@ -16,41 +16,52 @@ type StatusBar struct {
 	*ui.Block
 	keyBindings []string
 	text        string
+	warning     string
 	pause       bool
+	palette     console.Palette
 }
 
-func NewStatusBar(configFileName string, palette console.Palette) *StatusBar {
+func NewStatusBar(configFileName string, palette console.Palette, keys console.KeyMap) *StatusBar {
 
 	block := *ui.NewBlock()
 	block.Border = false
 	text := fmt.Sprintf(" %s %s @ %s", console.AppTitle, console.AppVersion, configFileName)
 
 	return &StatusBar{
-		Block: NewBlock("", false, palette),
-		text:  text,
+		Block:   NewBlock("", false, palette),
+		text:    text,
+		palette: palette,
 		keyBindings: []string{
-			"(q) quit",
-			"(p) pause",
-			"(<->) selection",
-			"(ESC) reset alerts",
+			fmt.Sprintf("(%s) quit", keys.GetHint(console.ActionQuit)),
+			fmt.Sprintf("(%s) help", keys.GetHint(console.ActionHelp)),
+			fmt.Sprintf("(%s) pause", keys.GetHint(console.ActionPause)),
+			fmt.Sprintf("(%s) add", keys.GetHint(console.ActionAdd)),
+			fmt.Sprintf("(%s) selection", keys.GetSelectionHint()),
+			fmt.Sprintf("(%s) reset alerts", keys.GetHint(console.ActionReset)),
 		},
 	}
 }
 
 func (s *StatusBar) Draw(buffer *ui.Buffer) {
 
-	buffer.Fill(ui.NewCell(' ', ui.NewStyle(console.ColorClear, console.GetMenuColorReverse())), s.GetRect())
+	buffer.Fill(ui.NewCell(' ', ui.NewStyle(console.ColorClear, s.palette.MenuColorReverse)), s.GetRect())
 
 	indent := bindingsIndent
 	for _, binding := range s.keyBindings {
-		buffer.SetString(binding, ui.NewStyle(console.GetMenuColor(), console.GetMenuColorReverse()), image.Pt(s.Max.X-len(binding)-indent, s.Min.Y))
+		buffer.SetString(binding, ui.NewStyle(s.palette.MenuColor, s.palette.MenuColorReverse), image.Pt(s.Max.X-len(binding)-indent, s.Min.Y))
 		indent += bindingsIndent + len(binding)
 	}
 
-	buffer.SetString(s.text, ui.NewStyle(console.GetMenuColor(), console.GetMenuColorReverse()), s.Min)
+	buffer.SetString(s.text, ui.NewStyle(s.palette.MenuColor, s.palette.MenuColorReverse), s.Min)
+
+	// warning takes the space between the text and the key bindings
+	if width := s.Max.X - indent - len(s.text); len(s.warning) > 0 && !s.pause && width > 0 {
+		buffer.SetString(ui.TrimString(s.warning, width), ui.NewStyle(console.ColorOrange, s.palette.MenuColorReverse),
+			image.Pt(s.Min.X+len(s.text)+bindingsIndent, s.Min.Y))
+	}
 
 	if s.pause {
-		buffer.SetString(pauseText, ui.NewStyle(console.GetMenuColorReverse(), console.GetMenuColor()), image.Pt(s.Max.X-s.Dx()/2-len(pauseText)/2, s.Min.Y))
+		buffer.SetString(pauseText, ui.NewStyle(s.palette.MenuColorReverse, s.palette.MenuColor), image.Pt(s.Max.X-s.Dx()/2-len(pauseText)/2, s.Min.Y))
 	}
 
 	s.Block.Draw(buffer)
@@ -59,3 +70,8 @@ func (s *StatusBar) Draw(buffer *ui.Buffer) {
 func (s *StatusBar) TogglePause() {
 	s.pause = !s.pause
 }
+
+// SetWarning shows the text until it's reset with an empty one
+func (s *StatusBar) SetWarning(text string) {
+	s.warning = text
+}
@@ -7,16 +7,22 @@ import (
 	"github.com/sqshq/sampler/console"
 	"github.com/sqshq/sampler/data"
 	"image"
+	"sync"
 )
 
-// TextBox represents a component with regular text
+const (
+	CommandScroll = "SCROLL"
+)
+
+// TextBox represents a component with regular text. Text, which doesn't fit the box, can be scrolled
 type TextBox struct {
 	*ui.Block
 	*data.Consumer
-	alert  *data.Alert
 	text   string
 	border bool
 	style  ui.Style
+	offset int
+	mutex  *sync.Mutex
 }
 
 func NewTextBox(c config.TextBoxConfig, palette console.Palette) *TextBox {
@@ -30,15 +36,24 @@ func NewTextBox(c config.TextBoxConfig, palette console.Palette) *TextBox {
 		Block:    component.NewBlock(c.Title, *c.Border, palette),
 		Consumer: data.NewConsumer(),
 		style:    ui.NewStyle(*color),
+		mutex:    &sync.Mutex{},
 	}
 
 	go func() {
 		for {
 			select {
 			case sample := <-box.SampleChannel:
+				box.mutex.Lock()
 				box.text = sample.Value
+				box.mutex.Unlock()
 			case alert := <-box.AlertChannel:
-				box.alert = alert
+				box.SetAlert(alert)
+			case command := <-box.CommandChannel:
+				if command.Type == CommandScroll {
+					box.mutex.Lock()
+					box.offset = ui.MaxInt(0, box.offset+command.Value.(int))
+					box.mutex.Unlock()
+				}
 			}
 		}
 	}()
@@ -50,11 +65,20 @@ func (t *TextBox) Draw(buffer *ui.Buffer) {
 
 	t.Block.Draw(buffer)
 
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
 	cells := ui.ParseStyles(t.text, ui.Theme.Paragraph.Text)
 	cells = ui.WrapCells(cells, uint(t.Inner.Dx()-2))
 
 	rows := ui.SplitCells(cells, '\n')
 
+	// the last inner line is a padding
+	if maxOffset := len(rows) - (t.Inner.Dy() - 1); t.offset > maxOffset {
+		t.offset = ui.MaxInt(0, maxOffset)
+	}
+	rows = rows[t.offset:]
+
 	for y, row := range rows {
 		if y+t.Inner.Min.Y >= t.Inner.Max.Y-1 {
 			break
@@ -67,5 +91,5 @@ func (t *TextBox) Draw(buffer *ui.Buffer) {
 		}
 	}
 
-	component.RenderAlert(t.alert, t.Rectangle, buffer)
+	component.RenderAlert(t.GetAlert(), t.Rectangle, buffer)
 }
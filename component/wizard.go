@@ -0,0 +1,200 @@
+package component
+
+import (
+	ui "github.com/gizak/termui/v3"
+	"github.com/sqshq/sampler/component/util"
+	"github.com/sqshq/sampler/config"
+	"github.com/sqshq/sampler/console"
+	"image"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Wizard walks through the component creation steps: type, title, sample script and rate
+type Wizard struct {
+	*ui.Block
+	palette console.Palette
+	step    wizardStep
+	option  int
+	input   string
+	draft   config.ComponentDraft
+	err     string
+}
+
+type wizardStep rune
+
+const (
+	wizardStepType   wizardStep = 0
+	wizardStepTitle  wizardStep = 1
+	wizardStepSample wizardStep = 2
+	wizardStepRate   wizardStep = 3
+)
+
+type wizardOption struct {
+	componentType config.ComponentType
+	name          string
+}
+
+var wizardOptions = []wizardOption{
+	{config.TypeRunChart, "RUNCHART"},
+	{config.TypeBarChart, "BARCHART"},
+	{config.TypeSparkLine, "SPARKLINE"},
+	{config.TypeGauge, "GAUGE"},
+	{config.TypeTextBox, "TEXTBOX"},
+	{config.TypeAsciiBox, "ASCIIBOX"},
+}
+
+var wizardPrompts = map[wizardStep]string{
+	wizardStepType:   "Component type:",
+	wizardStepTitle:  "Title:",
+	wizardStepSample: "Sample script:",
+	wizardStepRate:   "Sample rate, ms:",
+}
+
+const (
+	wizardWidth       = 60
+	wizardHeight      = 12
+	defaultWizardRate = "1000"
+)
+
+func NewWizard(palette console.Palette) *Wizard {
+	return &Wizard{
+		Block:   NewBlock("ADD COMPONENT", true, palette),
+		palette: palette,
+	}
+}
+
+// Open resets the wizard to the first step
+func (w *Wizard) Open() {
+	w.step = wizardStepType
+	w.option = 0
+	w.input = ""
+	w.err = ""
+	w.draft = config.ComponentDraft{}
+}
+
+// HandleKeyboardEvent returns true, when all the steps are completed and the draft is ready
+func (w *Wizard) HandleKeyboardEvent(e string) bool {
+
+	if w.step == wizardStepType {
+		switch e {
+		case console.KeyUp:
+			w.option = (w.option + len(wizardOptions) - 1) % len(wizardOptions)
+		case console.KeyDown:
+			w.option = (w.option + 1) % len(wizardOptions)
+		case console.KeyEnter:
+			w.draft.Type = wizardOptions[w.option].componentType
+			w.step = wizardStepTitle
+		}
+		return false
+	}
+
+	switch e {
+	case console.KeyEnter:
+		return w.submit()
+	case console.KeySpace:
+		w.input += " "
+	case console.KeyBackspace1, console.KeyBackspace2:
+		if len(w.input) > 0 {
+			_, size := utf8.DecodeLastRuneInString(w.input)
+			w.input = w.input[:len(w.input)-size]
+		}
+	default:
+		if utf8.RuneCountInString(e) == 1 {
+			w.input += e
+		}
+	}
+
+	return false
+}
+
+// submit completes the current step, and returns true if it was the last one
+func (w *Wizard) submit() bool {
+
+	value := strings.TrimSpace(w.input)
+	if len(value) == 0 {
+		w.err = "value should be specified"
+		return false
+	}
+
+	w.err = ""
+
+	switch w.step {
+	case wizardStepTitle:
+		w.draft.Title = value
+		w.step, w.input = wizardStepSample, ""
+	case wizardStepSample:
+		w.draft.Sample = value
+		w.step, w.input = wizardStepRate, defaultWizardRate
+	case wizardStepRate:
+		rate, err := strconv.Atoi(value)
+		if err != nil || rate <= 0 {
+			w.err = "rate should be a positive number of milliseconds"
+			return false
+		}
+		w.draft.RateMs = rate
+		return true
+	}
+
+	return false
+}
+
+func (w *Wizard) GetDraft() config.ComponentDraft {
+	return w.draft
+}
+
+// SetError shows the reason why the drafted component can't be created
+func (w *Wizard) SetError(err error) {
+	w.err = err.Error()
+}
+
+func (w *Wizard) Draw(buffer *ui.Buffer) {
+
+	buffer.Fill(ui.NewCell(' ', ui.NewStyle(w.palette.ReverseColor)), w.GetRect())
+	w.Block.Draw(buffer)
+
+	regularStyle := ui.NewStyle(w.palette.BaseColor, w.palette.ReverseColor)
+	highlightedStyle := ui.NewStyle(w.palette.ReverseColor, console.ColorOlive)
+	hintStyle := ui.NewStyle(console.ColorDarkGrey)
+
+	x, y := w.Inner.Min.X+2, w.Inner.Min.Y+1
+	buffer.SetString(wizardPrompts[w.step], regularStyle, image.Pt(x, y))
+
+	if w.step == wizardStepType {
+		for i, o := range wizardOptions {
+			style := regularStyle
+			if i == w.option {
+				style = highlightedStyle
+			}
+			buffer.SetString(o.name, style, image.Pt(x+len(wizardPrompts[w.step])+2, y+i))
+		}
+	} else {
+		buffer.SetString(w.getVisibleInput(w.Inner.Dx()-4), highlightedStyle, image.Pt(x, y+2))
+	}
+
+	if len(w.err) > 0 {
+		buffer.SetString(ui.TrimString(w.err, w.Inner.Dx()-4), ui.NewStyle(console.ColorOrange), image.Pt(x, w.Inner.Max.Y-3))
+	}
+
+	hint := "<ENTER> to continue, <ESC> to cancel"
+	buffer.SetString(hint, hintStyle, util.GetMiddlePoint(w.Inner, hint, w.Inner.Dy()/2-1))
+}
+
+// getVisibleInput returns the tail of the input with a cursor, which fits the width
+func (w *Wizard) getVisibleInput(width int) string {
+	runes := []rune(w.input + "_")
+	if width > 0 && len(runes) > width {
+		runes = runes[len(runes)-width:]
+	}
+	return string(runes)
+}
+
+// SetArea places the wizard in the middle of the area
+func (w *Wizard) SetArea(area image.Rectangle) {
+	width := ui.MinInt(wizardWidth, area.Dx())
+	height := ui.MinInt(wizardHeight, area.Dy())
+	x := area.Min.X + (area.Dx()-width)/2
+	y := area.Min.Y + (area.Dy()-height)/2
+	w.SetRect(x, y, x+width, y+height)
+}
@@ -4,28 +4,231 @@ import (
 	ui "github.com/gizak/termui/v3"
 	"github.com/sqshq/sampler/console"
 	"image"
+	"math"
+	"sort"
 )
 
+// LayoutMode defines whether component positions are taken from the config, or tiled automatically
+type LayoutMode string
+
+const (
+	LayoutManual LayoutMode = "manual"
+	LayoutAuto   LayoutMode = "auto"
+)
+
+// Tiling defines how the grid is split between the components in auto layout, or on TILE menu action
+type Tiling string
+
+const (
+	TilingGrid    Tiling = "grid"
+	TilingColumns Tiling = "columns"
+	TilingTreemap Tiling = "treemap"
+)
+
+var Tilings = []Tiling{TilingGrid, TilingColumns, TilingTreemap}
+
+const defaultWeight = 1
+
+func (c *Config) IsAutoLayout() bool {
+	return c.Layout != nil && *c.Layout == LayoutAuto
+}
+
+func (c *Config) GetTiling() Tiling {
+	if c.Tiling == nil {
+		return TilingGrid
+	}
+	return *c.Tiling
+}
+
+// GetWeight returns the component share of the grid in treemap tiling
+func (c *ComponentConfig) GetWeight() int {
+	if c.Weight == nil {
+		return defaultWeight
+	}
+	return *c.Weight
+}
+
+// Tile splits the whole grid between the components, so they don't overlap. Rectangles are returned
+// in the components order, and depend on the components count and weights only
+func Tile(weights []int, grid GridConfig, tiling Tiling) []image.Rectangle {
+
+	rectangles := make([]image.Rectangle, len(weights))
+	if len(weights) == 0 {
+		return rectangles
+	}
+
+	area := image.Rect(0, 0, grid.Columns, grid.Rows)
+	columns := int(math.Ceil(math.Sqrt(float64(len(weights)))))
+
+	switch tiling {
+	case TilingColumns:
+		// components fill the columns one by one, and share the column height
+		i := 0
+		for column := 0; column < columns; column++ {
+			count := len(weights) / columns
+			if column < len(weights)%columns {
+				count++
+			}
+			for row := 0; row < count; row++ {
+				rectangles[i] = image.Rect(
+					split(area.Dx(), columns, column), split(area.Dy(), count, row),
+					split(area.Dx(), columns, column+1), split(area.Dy(), count, row+1))
+				i++
+			}
+		}
+	case TilingTreemap:
+		indexes := make([]int, len(weights))
+		for i := range indexes {
+			indexes[i] = i
+		}
+		sort.SliceStable(indexes, func(i, j int) bool {
+			return weights[indexes[i]] > weights[indexes[j]]
+		})
+		tileTreemap(indexes, weights, area, rectangles)
+	default:
+		// components fill the rows one by one, and the last row is stretched
+		rows := (len(weights) + columns - 1) / columns
+		for i := range weights {
+			row, column := i/columns, i%columns
+			count := ui.MinInt(columns, len(weights)-row*columns)
+			rectangles[i] = image.Rect(
+				split(area.Dx(), count, column), split(area.Dy(), rows, row),
+				split(area.Dx(), count, column+1), split(area.Dy(), rows, row+1))
+		}
+	}
+
+	return rectangles
+}
+
+// tileTreemap splits the components into two groups of a similar weight, and the area between
+// them proportionally along its longer side, until each component gets its own rectangle
+func tileTreemap(indexes []int, weights []int, area image.Rectangle, rectangles []image.Rectangle) {
+
+	if len(indexes) == 1 {
+		rectangles[indexes[0]] = area
+		return
+	}
+
+	total := 0
+	for _, i := range indexes {
+		total += weights[i]
+	}
+
+	at, first := 1, weights[indexes[0]]
+	for at < len(indexes)-1 && ui.AbsInt(total-2*(first+weights[indexes[at]])) < ui.AbsInt(total-2*first) {
+		first += weights[indexes[at]]
+		at++
+	}
+
+	a, b := area, area
+	if area.Dx() >= area.Dy() {
+		x := area.Min.X + ui.MaxInt(1, ui.MinInt(area.Dx()-1, area.Dx()*first/total))
+		a.Max.X, b.Min.X = x, x
+	} else {
+		y := area.Min.Y + ui.MaxInt(1, ui.MinInt(area.Dy()-1, area.Dy()*first/total))
+		a.Max.Y, b.Min.Y = y, y
+	}
+
+	tileTreemap(indexes[:at], weights, a, rectangles)
+	tileTreemap(indexes[at:], weights, b, rectangles)
+}
+
+// split returns the boundary of the part, when the length is split into equal parts
+func split(length int, parts int, part int) int {
+	return length * part / parts
+}
+
+// GetOverlaps returns pairs of indexes of the overlapping rectangles
+func GetOverlaps(rectangles []image.Rectangle) [][2]int {
+	var overlaps [][2]int
+	for i, a := range rectangles {
+		for j, b := range rectangles[:i] {
+			if a.Overlaps(b) {
+				overlaps = append(overlaps, [2]int{j, i})
+			}
+		}
+	}
+	return overlaps
+}
+
+// GetGrid returns the logical grid size, 80x40 by default
+func (c *Config) GetGrid() GridConfig {
+	grid := GridConfig{Columns: console.ColumnsCount, Rows: console.RowsCount}
+	if c.Grid != nil && c.Grid.Columns > 0 {
+		grid.Columns = c.Grid.Columns
+	}
+	if c.Grid != nil && c.Grid.Rows > 0 {
+		grid.Rows = c.Grid.Rows
+	}
+	return grid
+}
+
+// GetBreakpoint returns the breakpoint with the smallest max width, which fits the terminal width.
+// Empty name is returned for the default positions, if the terminal is wider than all the breakpoints
+func GetBreakpoint(breakpoints map[string]int, width int) string {
+
+	var names []string
+	for name := range breakpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := ""
+	for _, name := range names {
+		if maxWidth := breakpoints[name]; width <= maxWidth && (len(result) == 0 || maxWidth < breakpoints[result]) {
+			result = name
+		}
+	}
+
+	return result
+}
+
 func (c *Config) setDefaultArrangement() {
 
+	grid := c.GetGrid()
 	components := getComponents(c)
 
+	if c.IsAutoLayout() {
+		c.setTiledArrangement(components, grid)
+		return
+	}
+
 	if allHaveNoPosition(components) {
-		setSingleComponentPosition(components[0])
+		setSingleComponentPosition(components[0], grid)
 	}
 
 	for _, component := range components {
 		if component.Position == nil || len(component.Position) == 0 {
+			arrange(component, components, grid)
+		}
+	}
+}
 
-			lc := getLargestComponent(components)
-			le := getLargestEmptySpaceRectangle(components)
+// setTiledArrangement replaces positions from the config, including the breakpoint ones
+func (c *Config) setTiledArrangement(components []*ComponentConfig, grid GridConfig) {
 
-			if getSquare(lc) > le.Dx()*le.Dy()*2 {
-				arrangeIntoLargestComponent(component, lc)
-			} else {
-				arrangeIntoLargestEmptySpace(component, le)
-			}
-		}
+	weights := make([]int, len(components))
+	for i, component := range components {
+		weights[i] = component.GetWeight()
+	}
+
+	for i, r := range Tile(weights, grid, c.GetTiling()) {
+		components[i].Position = getPosition(Location{X: r.Min.X, Y: r.Min.Y}, Size{X: r.Dx(), Y: r.Dy()})
+		components[i].Positions = nil
+	}
+}
+
+// arrange places the component into the largest empty space,
+// or splits the largest component, if it's much bigger than the empty space
+func arrange(component *ComponentConfig, components []*ComponentConfig, grid GridConfig) {
+
+	lc := getLargestComponent(components)
+	le := getLargestEmptySpaceRectangle(components, grid)
+
+	if getSquare(lc) > le.Dx()*le.Dy()*2 {
+		arrangeIntoLargestComponent(component, lc)
+	} else {
+		arrangeIntoLargestEmptySpace(component, le)
 	}
 }
 
@@ -115,32 +318,35 @@ func getComponents(c *Config) []*ComponentConfig {
 	return components
 }
 
-func setSingleComponentPosition(c *ComponentConfig) {
-	w := int(console.ColumnsCount)
-	h := int(console.RowsCount * 0.6)
+func setSingleComponentPosition(c *ComponentConfig, grid GridConfig) {
+	w := grid.Columns
+	h := int(float64(grid.Rows) * 0.6)
 	c.Position = [][]int{
-		{(console.ColumnsCount - w) / 2, (console.RowsCount - h) / 2},
+		{(grid.Columns - w) / 2, (grid.Rows - h) / 2},
 		{w, h},
 	}
 }
 
-func getLargestEmptySpaceRectangle(components []*ComponentConfig) image.Rectangle {
+func getLargestEmptySpaceRectangle(components []*ComponentConfig, grid GridConfig) image.Rectangle {
 
-	grid := [console.RowsCount][console.ColumnsCount]int{}
+	cells := make([][]int, grid.Rows)
+	for r := range cells {
+		cells[r] = make([]int, grid.Columns)
+	}
 
 	for _, component := range components {
 		rect := component.GetRectangle()
-		for r := ui.MinInt(console.RowsCount, rect.Min.Y); r < ui.MinInt(console.RowsCount, rect.Max.Y); r++ {
-			for c := ui.MinInt(console.ColumnsCount, rect.Min.X); c < ui.MinInt(console.ColumnsCount, rect.Max.X); c++ {
-				grid[r][c] = 1
+		for r := ui.MaxInt(0, ui.MinInt(grid.Rows, rect.Min.Y)); r < ui.MinInt(grid.Rows, rect.Max.Y); r++ {
+			for c := ui.MaxInt(0, ui.MinInt(grid.Columns, rect.Min.X)); c < ui.MinInt(grid.Columns, rect.Max.X); c++ {
+				cells[r][c] = 1
 			}
 		}
 	}
 
 	mr := image.ZR
 
-	for row := 0; row < console.RowsCount; row++ {
-		histogram := createHistogram(grid, row)
+	for row := 0; row < grid.Rows; row++ {
+		histogram := createHistogram(cells, row)
 		r := calcMaxRectangle(histogram, row)
 		if r.Dx()*r.Dy() > mr.Dx()*mr.Dy() {
 			mr = r
@@ -150,7 +356,7 @@ func getLargestEmptySpaceRectangle(components []*ComponentConfig) image.Rectangl
 	return mr
 }
 
-func calcMaxRectangle(histogram [console.ColumnsCount]int, row int) image.Rectangle {
+func calcMaxRectangle(histogram []int, row int) image.Rectangle {
 
 	maxRectangle := image.ZR
 	maxArea := 0
@@ -176,18 +382,18 @@ func calcMaxRectangle(histogram [console.ColumnsCount]int, row int) image.Rectan
 	return maxRectangle
 }
 
-func createHistogram(grid [console.RowsCount][console.ColumnsCount]int, row int) [console.ColumnsCount]int {
-	histogram := [console.ColumnsCount]int{}
-	for column := 0; column < console.ColumnsCount; column++ {
-		histogram[column] = countEmptyCellsBelow(grid, row, column)
+func createHistogram(cells [][]int, row int) []int {
+	histogram := make([]int, len(cells[row]))
+	for column := range histogram {
+		histogram[column] = countEmptyCellsBelow(cells, row, column)
 	}
 	return histogram
 }
 
-func countEmptyCellsBelow(grid [console.RowsCount][console.ColumnsCount]int, row int, column int) int {
+func countEmptyCellsBelow(cells [][]int, row int, column int) int {
 	count := 0
-	for r := row; r < console.RowsCount; r++ {
-		if grid[r][column] == 1 {
+	for r := row; r < len(cells); r++ {
+		if cells[r][column] == 1 {
 			return count
 		}
 		count++
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"image"
+	"reflect"
+	"testing"
+)
+
+func TestGetBreakpoint(t *testing.T) {
+
+	breakpoints := map[string]int{"narrow": 120, "tiny": 80, "half": 120}
+
+	tests := []struct {
+		width int
+		want  string
+	}{
+		{200, ""},
+		{121, ""},
+		{120, "half"},
+		{100, "half"},
+		{80, "tiny"},
+		{20, "tiny"},
+	}
+
+	for _, tt := range tests {
+		if got := GetBreakpoint(breakpoints, tt.width); got != tt.want {
+			t.Errorf("GetBreakpoint(%d) = %q, want %q", tt.width, got, tt.want)
+		}
+	}
+
+	if got := GetBreakpoint(nil, 100); got != "" {
+		t.Errorf("GetBreakpoint() without breakpoints = %q, want default", got)
+	}
+}
+
+func TestConfig_GetGrid(t *testing.T) {
+
+	tests := []struct {
+		grid *GridConfig
+		want GridConfig
+	}{
+		{nil, GridConfig{Columns: 80, Rows: 40}},
+		{&GridConfig{Columns: 160}, GridConfig{Columns: 160, Rows: 40}},
+		{&GridConfig{Columns: 120, Rows: 60}, GridConfig{Columns: 120, Rows: 60}},
+	}
+
+	for _, tt := range tests {
+		cfg := Config{Grid: tt.grid}
+		if got := cfg.GetGrid(); got != tt.want {
+			t.Errorf("GetGrid() = %v, want %v", got, tt.want)
+		}
+	}
+}
+
+func TestTile(t *testing.T) {
+
+	grid := GridConfig{Columns: 80, Rows: 40}
+
+	tests := []struct {
+		name    string
+		weights []int
+		tiling  Tiling
+		want    []image.Rectangle
+	}{
+		{"grid", []int{1, 1, 1}, TilingGrid, []image.Rectangle{
+			image.Rect(0, 0, 40, 20), image.Rect(40, 0, 80, 20), image.Rect(0, 20, 80, 40)}},
+		{"columns", []int{1, 1, 1}, TilingColumns, []image.Rectangle{
+			image.Rect(0, 0, 40, 20), image.Rect(0, 20, 40, 40), image.Rect(40, 0, 80, 40)}},
+		{"treemap", []int{1, 3, 1}, TilingTreemap, []image.Rectangle{
+			image.Rect(48, 0, 80, 20), image.Rect(0, 0, 48, 40), image.Rect(48, 20, 80, 40)}},
+		{"single", []int{2}, TilingTreemap, []image.Rectangle{image.Rect(0, 0, 80, 40)}},
+		{"unknown tiling is grid", []int{1, 1}, Tiling("spiral"), []image.Rectangle{
+			image.Rect(0, 0, 40, 40), image.Rect(40, 0, 80, 40)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Tile(tt.weights, grid, tt.tiling); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTile_coversGrid(t *testing.T) {
+
+	grid := GridConfig{Columns: 80, Rows: 40}
+	weights := []int{5, 1, 2, 8, 1, 1, 3}
+
+	for _, tiling := range Tilings {
+		for n := 1; n <= len(weights); n++ {
+
+			rectangles := Tile(weights[:n], grid, tiling)
+
+			area := 0
+			for _, r := range rectangles {
+				if r.Empty() || !r.In(image.Rect(0, 0, grid.Columns, grid.Rows)) {
+					t.Errorf("Tile(%s, %d) rectangle %v is empty or outside of the grid", tiling, n, r)
+				}
+				area += r.Dx() * r.Dy()
+			}
+
+			if overlaps := GetOverlaps(rectangles); len(overlaps) > 0 {
+				t.Errorf("Tile(%s, %d) = %v has overlaps %v", tiling, n, rectangles, overlaps)
+			}
+			if area != grid.Columns*grid.Rows {
+				t.Errorf("Tile(%s, %d) = %v covers %d cells, want %d", tiling, n, rectangles, area, grid.Columns*grid.Rows)
+			}
+			if again := Tile(weights[:n], grid, tiling); !reflect.DeepEqual(again, rectangles) {
+				t.Errorf("Tile(%s, %d) isn't deterministic: %v and %v", tiling, n, rectangles, again)
+			}
+		}
+	}
+}
+
+func TestGetOverlaps(t *testing.T) {
+
+	rectangles := []image.Rectangle{
+		image.Rect(0, 0, 40, 20),
+		image.Rect(40, 0, 80, 20),
+		image.Rect(30, 10, 50, 30),
+		image.Rect(0, 30, 10, 40),
+	}
+
+	want := [][2]int{{0, 2}, {1, 2}}
+	if got := GetOverlaps(rectangles); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetOverlaps() = %v, want %v", got, want)
+	}
+}
+
+func TestConfig_setDefaultArrangement_auto(t *testing.T) {
+
+	auto := LayoutAuto
+	weight := 3
+	cfg := Config{
+		Layout: &auto,
+		RunCharts: []RunChartConfig{
+			{ComponentConfig: ComponentConfig{Title: "Latency", Position: [][]int{{0, 0}, {10, 10}}, Weight: &weight}},
+		},
+		TextBoxes: []TextBoxConfig{
+			{ComponentConfig: ComponentConfig{Title: "Status", Positions: map[string][][]int{"narrow": {{0, 0}, {10, 10}}}}},
+		},
+	}
+
+	cfg.setDefaultArrangement()
+
+	if got := cfg.RunCharts[0].GetRectangle(); got != image.Rect(0, 0, 40, 40) {
+		t.Errorf("setDefaultArrangement() Latency = %v, want tiled position", got)
+	}
+	if got := cfg.TextBoxes[0].GetRectangle(); got != image.Rect(40, 0, 80, 40) {
+		t.Errorf("setDefaultArrangement() Status = %v, want tiled position", got)
+	}
+	if cfg.TextBoxes[0].Positions != nil {
+		t.Errorf("setDefaultArrangement() kept breakpoint positions in auto layout")
+	}
+}
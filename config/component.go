@@ -18,11 +18,14 @@ const (
 )
 
 type ComponentConfig struct {
-	Title    string          `yaml:"title"`
-	Position [][]int         `yaml:"position,flow"`
-	RateMs   *int            `yaml:"rate-ms,omitempty"`
-	Triggers []TriggerConfig `yaml:"triggers,omitempty"`
-	Type     ComponentType   `yaml:",omitempty"`
+	Title     string             `yaml:"title"`
+	Position  [][]int            `yaml:"position,flow"`
+	Positions map[string][][]int `yaml:"positions,omitempty"`
+	Weight    *int               `yaml:"weight,omitempty"`
+	RateMs    *int               `yaml:"rate-ms,omitempty"`
+	Source    *SourceConfig      `yaml:"source,omitempty"`
+	Triggers  []TriggerConfig    `yaml:"triggers,omitempty"`
+	Type      ComponentType      `yaml:",omitempty"`
 }
 
 func (c *ComponentConfig) GetLocation() Location {
@@ -33,6 +36,15 @@ func (c *ComponentConfig) GetSize() Size {
 	return Size{X: c.Position[1][0], Y: c.Position[1][1]}
 }
 
+// GetPositionAt returns the component position for the breakpoint, if it's specified
+func (c *ComponentConfig) GetPositionAt(breakpoint string) (Location, Size, bool) {
+	p, ok := c.Positions[breakpoint]
+	if !ok || len(p) != 2 || len(p[0]) != 2 || len(p[1]) != 2 {
+		return Location{}, Size{}, false
+	}
+	return Location{X: p[0][0], Y: p[0][1]}, Size{X: p[1][0], Y: p[1][1]}, true
+}
+
 func (c *ComponentConfig) GetRectangle() image.Rectangle {
 	if c.Position == nil || len(c.Position) == 0 {
 		return image.ZR
@@ -44,6 +56,15 @@ func (c *ComponentConfig) GetRectangle() image.Rectangle {
 		c.Position[0][1]+c.Position[1][1])
 }
 
+// SourceConfig describes where samples are pushed to by external processes,
+// in a form of "label value" lines. Exactly one of the fields is expected
+type SourceConfig struct {
+	Fifo *string `yaml:"fifo,omitempty"`
+	File *string `yaml:"file,omitempty"`
+	Unix *string `yaml:"unix,omitempty"`
+	Tcp  *string `yaml:"tcp,omitempty"`
+}
+
 type TriggerConfig struct {
 	Title     string         `yaml:"title"`
 	Condition string         `yaml:"condition"`
@@ -62,6 +83,7 @@ type GaugeConfig struct {
 	Scale           *int      `yaml:"scale,omitempty"`
 	Color           *ui.Color `yaml:"color,omitempty"`
 	PercentOnly     *bool     `yaml:"percent-only,omitempty"`
+	Ssh             *string   `yaml:"ssh,omitempty"`
 	Cur             Item      `yaml:"cur"`
 	Max             Item      `yaml:"max"`
 	Min             Item      `yaml:"min"`
@@ -76,8 +98,9 @@ type SparkLineConfig struct {
 
 type BarChartConfig struct {
 	ComponentConfig `yaml:",inline"`
-	Scale           *int   `yaml:"scale,omitempty"`
-	Items           []Item `yaml:"items"`
+	Scale           *int    `yaml:"scale,omitempty"`
+	Ssh             *string `yaml:"ssh,omitempty"`
+	Items           []Item  `yaml:"items"`
 }
 
 type AsciiBoxConfig struct {
@@ -97,22 +120,116 @@ type RunChartConfig struct {
 	ComponentConfig `yaml:",inline"`
 	Legend          *LegendConfig `yaml:"legend,omitempty"`
 	Scale           *int          `yaml:"scale,omitempty"`
+	Ssh             *string       `yaml:"ssh,omitempty"`
 	Items           []Item        `yaml:"items"`
 }
 
+// LineStyle is a run chart line pattern
+type LineStyle string
+
+const (
+	LineSolid  LineStyle = "solid"
+	LineDashed LineStyle = "dashed"
+	LineDotted LineStyle = "dotted"
+)
+
+var LineStyles = []LineStyle{LineSolid, LineDashed, LineDotted}
+
 type LegendConfig struct {
 	Enabled bool `yaml:"enabled"`
 	Details bool `yaml:"details"`
 }
 
 type Item struct {
-	Label               *string   `yaml:"label,omitempty"`
-	Color               *ui.Color `yaml:"color,omitempty"`
-	Pty                 *bool     `yaml:"pty,omitempty"`
-	InitScript          *string   `yaml:"init,omitempty"`
-	MultiStepInitScript *[]string `yaml:"multistep-init,omitempty"`
-	SampleScript        *string   `yaml:"sample"`
-	TransformScript     *string   `yaml:"transform,omitempty"`
+	Label               *string           `yaml:"label,omitempty"`
+	Color               *ui.Color         `yaml:"color,omitempty"`
+	Line                *LineStyle        `yaml:"line,omitempty"`
+	Marker              *string           `yaml:"marker,omitempty"`
+	Pty                 *bool             `yaml:"pty,omitempty"`
+	InitScript          *string           `yaml:"init,omitempty"`
+	MultiStepInitScript *[]string         `yaml:"multistep-init,omitempty"`
+	SampleScript        *string           `yaml:"sample,omitempty"`
+	TransformScript     *string           `yaml:"transform,omitempty"`
+	Transforms          []TransformConfig `yaml:"transforms,omitempty"`
+	Completion          *CompletionConfig `yaml:"completion,omitempty"`
+	Ssh                 *string           `yaml:"ssh,omitempty"`
+	Statsd              *string           `yaml:"statsd,omitempty"`
+	Prometheus          *PrometheusConfig `yaml:"prometheus,omitempty"`
+	Sql                 *SqlConfig        `yaml:"sql,omitempty"`
+}
+
+// SqlConfig describes a database query. Variables and environment
+// variables in DSN are expanded, e.g. "postgres://app:$PGPASSWORD@db/app"
+type SqlConfig struct {
+	Driver SqlDriver `yaml:"driver"`
+	Dsn    string    `yaml:"dsn"`
+	Query  string    `yaml:"query"`
+	Rows   *SqlRows  `yaml:"rows,omitempty"`
+}
+
+type SqlDriver string
+
+const (
+	SqlDriverPostgres SqlDriver = "postgres"
+	SqlDriverSqlite   SqlDriver = "sqlite3"
+)
+
+var SqlDrivers = []SqlDriver{SqlDriverPostgres, SqlDriverSqlite}
+
+// SqlRows defines how a row set is presented: as a text table,
+// or as a bar per row, labeled by the first column
+type SqlRows string
+
+const (
+	SqlRowsTable SqlRows = "table"
+	SqlRowsBars  SqlRows = "bars"
+)
+
+// PrometheusConfig describes a metric, scraped from the endpoint in Prometheus text
+// exposition format. Query is a metric name with optional label matchers and rate()
+type PrometheusConfig struct {
+	Url   string `yaml:"url"`
+	Query string `yaml:"query"`
+}
+
+// CompletionConfig describes how to detect the end of a command
+// output in the interactive shell. Either marker or prompt is expected
+type CompletionConfig struct {
+	Marker    *string `yaml:"marker,omitempty"`
+	Prompt    *string `yaml:"prompt,omitempty"`
+	TimeoutMs *int    `yaml:"timeout-ms,omitempty"`
+}
+
+// MarkerPlaceholder is replaced with a unique value in the completion marker script
+const MarkerPlaceholder = "$marker"
+
+// TransformConfig describes a single step of the sample transformation pipeline.
+// Exactly one of the fields is expected to be specified
+type TransformConfig struct {
+	Regex    *string        `yaml:"regex,omitempty"`
+	JsonPath *string        `yaml:"json,omitempty"`
+	Unit     *TransformUnit `yaml:"unit,omitempty"`
+	Multiply *float64       `yaml:"multiply,omitempty"`
+	Add      *float64       `yaml:"add,omitempty"`
+	Trim     *string        `yaml:"trim,omitempty"`
+	Script   *string        `yaml:"script,omitempty"`
+}
+
+type TransformUnit string
+
+const (
+	UnitBytesToKiB TransformUnit = "bytes-to-kib"
+	UnitBytesToMiB TransformUnit = "bytes-to-mib"
+	UnitBytesToGiB TransformUnit = "bytes-to-gib"
+	UnitNsToMs     TransformUnit = "ns-to-ms"
+	UnitUsToMs     TransformUnit = "us-to-ms"
+	UnitMsToS      TransformUnit = "ms-to-s"
+	UnitSToMin     TransformUnit = "s-to-min"
+	UnitMsToMin    TransformUnit = "ms-to-min"
+)
+
+var TransformUnits = []TransformUnit{
+	UnitBytesToKiB, UnitBytesToMiB, UnitBytesToGiB, UnitNsToMs, UnitUsToMs, UnitMsToS, UnitSToMin, UnitMsToMin,
 }
 
 type Location struct {
@@ -125,11 +242,16 @@ type Size struct {
 	Y int
 }
 
+// ComponentSettings describes changes of the component, which are saved to the config file
 type ComponentSettings struct {
-	Type     ComponentType
-	Title    string
-	Size     Size
-	Location Location
+	Type       ComponentType
+	Title      string
+	Size       Size
+	Location   Location
+	Breakpoint string          // position is saved for the breakpoint, if specified
+	CopyOf     *string         // title of the component, which this one was duplicated from
+	Draft      *ComponentDraft // component, which was created from UI and isn't in the config file yet
+	Deleted    bool
 }
 
 func getPosition(location Location, size Size) [][]int {
@@ -8,23 +8,46 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 )
 
 type Config struct {
-	Theme      *console.Theme    `yaml:"theme,omitempty"`
-	Variables  map[string]string `yaml:"variables,omitempty"`
-	RunCharts  []RunChartConfig  `yaml:"runcharts,omitempty"`
-	BarCharts  []BarChartConfig  `yaml:"barcharts,omitempty"`
-	Gauges     []GaugeConfig     `yaml:"gauges,omitempty"`
-	SparkLines []SparkLineConfig `yaml:"sparklines,omitempty"`
-	TextBoxes  []TextBoxConfig   `yaml:"textboxes,omitempty"`
-	AsciiBoxes []AsciiBoxConfig  `yaml:"asciiboxes,omitempty"`
+	Theme       *console.Theme              `yaml:"theme,omitempty"`
+	Themes      map[string]ThemeConfig      `yaml:"themes,omitempty"`
+	Layout      *LayoutMode                 `yaml:"layout,omitempty"`
+	Tiling      *Tiling                     `yaml:"tiling,omitempty"`
+	Grid        *GridConfig                 `yaml:"grid,omitempty"`
+	Breakpoints map[string]int              `yaml:"breakpoints,omitempty"`
+	Keys        map[console.Action][]string `yaml:"keys,omitempty"`
+	Variables   map[string]string           `yaml:"variables,omitempty"`
+	Statsd      *StatsdConfig               `yaml:"statsd,omitempty"`
+	RunCharts   []RunChartConfig            `yaml:"runcharts,omitempty"`
+	BarCharts   []BarChartConfig            `yaml:"barcharts,omitempty"`
+	Gauges      []GaugeConfig               `yaml:"gauges,omitempty"`
+	SparkLines  []SparkLineConfig           `yaml:"sparklines,omitempty"`
+	TextBoxes   []TextBoxConfig             `yaml:"textboxes,omitempty"`
+	AsciiBoxes  []AsciiBoxConfig            `yaml:"asciiboxes,omitempty"`
+}
+
+// GridConfig describes the logical grid, which positions refer to. The grid is stretched to the terminal size
+type GridConfig struct {
+	Columns int `yaml:"columns,omitempty"`
+	Rows    int `yaml:"rows,omitempty"`
+}
+
+// StatsdConfig describes the built-in StatsD listener
+type StatsdConfig struct {
+	Addr    string `yaml:"addr"`
+	FlushMs *int   `yaml:"flush-ms,omitempty"`
 }
 
 func LoadConfig() (*Config, Options) {
 
 	var opt Options
-	_, err := flags.Parse(&opt)
+	args, err := flags.Parse(&opt)
 
 	if err != nil {
 		console.Exit("")
@@ -34,63 +57,405 @@ func LoadConfig() (*Config, Options) {
 		console.Exit(console.AppVersion)
 	}
 
+	if len(args) > 0 && args[0] == schemaCommand {
+		printSchema()
+	}
+
+	if len(args) > 0 && args[0] == validateCommand {
+		if opt.ConfigFile == nil && len(args) > 1 {
+			opt.ConfigFile = &args[1]
+		}
+		if opt.ConfigFile == nil {
+			console.Exit("Please specify config file to validate. Example: sampler validate --config example.yml")
+		}
+		runValidation(*opt.ConfigFile)
+	}
+
 	if opt.ConfigFile == nil {
 		console.Exit("Please specify config file using --config flag. Example: sampler --config example.yml")
 	}
 
-	cfg := readFile(opt.ConfigFile)
-	cfg.validate()
+	// warnings don't prevent the start, but are reported the same way, e.g. overlapping components
+	cfg, problems := load(*opt.ConfigFile)
+	for _, p := range problems {
+		_, _ = fmt.Fprintln(os.Stderr, p.Format(*opt.ConfigFile))
+	}
+	if cfg == nil || hasErrors(problems) {
+		os.Exit(1)
+	}
+
 	cfg.setDefaults()
 
 	return cfg, opt
 }
 
+const validateCommand = "validate"
+
+// runValidation prints all the problems found in the config file, and exits
+// with non-zero status if there are any, so it can be used in pre-commit hooks
+func runValidation(location string) {
+
+	problems := Validate(location)
+
+	for _, p := range problems {
+		fmt.Println(p.Format(location))
+	}
+
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+
+	fmt.Printf("%s: OK\n", location)
+	os.Exit(0)
+}
+
+// printSchema prints JSON Schema of the config file, e.g. to reference it from the editor settings
+func printSchema() {
+
+	content, err := Schema()
+	if err != nil {
+		log.Fatalf("Failed to generate config schema: %v", err)
+	}
+
+	fmt.Println(string(content))
+	os.Exit(0)
+}
+
+// Validate reads the config file and returns all the problems found, including warnings
+func Validate(location string) []Problem {
+	_, problems := load(location)
+	return problems
+}
+
+// load reads and validates the config file. Config is nil, if the file can't be parsed
+func load(location string) (*Config, []Problem) {
+
+	v := &validator{}
+
+	content, err := ioutil.ReadFile(location)
+	if err != nil {
+		v.errorf(path{}, "failed to read config file: %v", err)
+		return nil, v.problems
+	}
+
+	return parse(content, filepath.Dir(location))
+}
+
+// parse decodes and validates the config content. Config is nil, if the content can't be parsed.
+// Files, which the config refers to, are looked up in the config file directory
+func parse(content []byte, dir string) (*Config, []Problem) {
+
+	v := &validator{}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		v.reportYamlError(err)
+		return nil, v.sortedProblems()
+	}
+	v.root = &root
+
+	cfg := new(Config)
+	if err := root.Decode(cfg); err != nil {
+		v.reportYamlError(err)
+		if _, ok := err.(*yaml.TypeError); !ok {
+			return nil, v.sortedProblems()
+		}
+	}
+
+	// unknown keys are usually typos, e.g. rate_ms instead of rate-ms
+	v.validateKeys(&root, reflect.TypeOf(cfg), SeverityError)
+	cfg.loadThemeFiles(v, dir)
+	cfg.validate(v)
+
+	return cfg, v.sortedProblems()
+}
+
+func hasErrors(problems []Problem) bool {
+	for _, p := range problems {
+		if p.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Update writes components positions, new components, duplicates and deletions to the config file. Nodes are located
+// in the yaml node tree, and only their lines are changed, so comments, keys ordering, anchors,
+// formatting and unknown fields are preserved
 func Update(settings []ComponentSettings, options Options) {
-	cfg := readFile(options.ConfigFile)
-	for _, s := range settings {
-		componentConfig := cfg.findComponent(s.Type, s.Title)
-		componentConfig.Position = getPosition(s.Location, s.Size)
+
+	content, err := ioutil.ReadFile(*options.ConfigFile)
+	if err != nil {
+		log.Fatalf("Failed to read config file: %s", *options.ConfigFile)
+	}
+
+	content, err = updateContent(content, settings)
+	if err != nil {
+		log.Fatalf("Failed to read config file: %v", err)
+	}
+
+	if err = writeFileAtomically(*options.ConfigFile, content); err != nil {
+		log.Fatalf("Failed to save config file: %v", err)
 	}
-	saveFile(cfg, options.ConfigFile)
 }
 
-func (c *Config) findComponent(componentType ComponentType, componentTitle string) *ComponentConfig {
+// updateContent applies the changes one by one, re-reading the node tree after each of them.
+// New components and duplicates are added first, since the components they were copied from can be
+// deleted. Breakpoint positions of the added components are updated after they are added
+func updateContent(content []byte, settings []ComponentSettings) ([]byte, error) {
+
+	var err error
+	var moved []ComponentSettings
 
-	switch componentType {
-	case TypeRunChart:
-		for i, component := range c.RunCharts {
-			if component.Title == componentTitle {
-				return &c.RunCharts[i].ComponentConfig
+	for _, s := range settings {
+		added := s.CopyOf != nil || s.Draft != nil
+		if s.Draft != nil && len(s.Breakpoint) == 0 && !s.Deleted {
+			entry := formatComponent(*s.Draft, formatPosition(s.Location, s.Size))
+			if content, err = appendComponent(content, s.Type, entry); err != nil {
+				return nil, err
 			}
-		}
-	case TypeBarChart:
-		for i, component := range c.BarCharts {
-			if component.Title == componentTitle {
-				return &c.BarCharts[i].ComponentConfig
+		} else if s.CopyOf != nil && len(s.Breakpoint) == 0 && !s.Deleted {
+			if content, err = copyComponent(content, s); err != nil {
+				return nil, err
 			}
 		}
-	case TypeGauge:
-		for i, component := range c.Gauges {
-			if component.Title == componentTitle {
-				return &c.Gauges[i].ComponentConfig
+		if (!added || len(s.Breakpoint) > 0) && !s.Deleted {
+			moved = append(moved, s)
+		}
+	}
+
+	if content, err = updatePositions(content, moved); err != nil {
+		return nil, err
+	}
+
+	for _, s := range settings {
+		if s.CopyOf == nil && s.Deleted {
+			if content, err = deleteComponent(content, s); err != nil {
+				return nil, err
 			}
 		}
-	case TypeSparkLine:
-		for i, component := range c.SparkLines {
-			if component.Title == componentTitle {
-				return &c.SparkLines[i].ComponentConfig
+	}
+
+	return content, nil
+}
+
+// updatePositions edits the default positions at once, and then the breakpoint positions one by one,
+// since a breakpoint position can add the positions mapping, which the next one is added to
+func updatePositions(content []byte, settings []ComponentSettings) ([]byte, error) {
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(string(content), "\n")
+	var edits []lineEdit
+
+	for _, s := range settings {
+		if len(s.Breakpoint) == 0 {
+			component := findComponentNode(&root, s.Type, s.Title)
+			edits = append(edits, getPositionEdit(component, lines, formatPosition(s.Location, s.Size)))
+		}
+	}
+
+	content = []byte(strings.Join(applyEdits(lines, edits), "\n"))
+
+	for _, s := range settings {
+		if len(s.Breakpoint) > 0 {
+			var err error
+			if content, err = updateBreakpointPosition(content, s); err != nil {
+				return nil, err
 			}
 		}
-	case TypeAsciiBox:
-		for i, component := range c.AsciiBoxes {
-			if component.Title == componentTitle {
-				return &c.AsciiBoxes[i].ComponentConfig
+	}
+
+	return content, nil
+}
+
+func updateBreakpointPosition(content []byte, settings ComponentSettings) ([]byte, error) {
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(string(content), "\n")
+	component := findComponentNode(&root, settings.Type, settings.Title)
+
+	edit, err := getBreakpointPositionEdit(component, lines, settings.Breakpoint, formatPosition(settings.Location, settings.Size))
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(strings.Join(applyEdits(lines, []lineEdit{edit}), "\n")), nil
+}
+
+// copyComponent adds a copy of the component lines to the end of its section, with the new title and position
+func copyComponent(content []byte, settings ComponentSettings) ([]byte, error) {
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(string(content), "\n")
+	origin := findComponentNode(&root, settings.Type, *settings.CopyOf)
+	from, to := origin.Line-1, getNodeEnd(origin, lines)
+
+	edits := []lineEdit{getPositionEdit(origin, lines, formatPosition(settings.Location, settings.Size))}
+	if title := getMappingValue(origin, "title"); title != nil {
+		line := lines[title.Line-1][:title.Column-1] + formatScalar(settings.Title)
+		if len(title.LineComment) > 0 {
+			line += " " + title.LineComment
+		}
+		edits = append(edits, lineEdit{from: title.Line - 1, to: title.Line, line: line})
+	}
+
+	updated := applyEdits(append([]string{}, lines...), edits)
+	copied := updated[from : to+len(updated)-len(lines)]
+
+	// keys start at the node column. The first line contains the sequence dash, and the anchor,
+	// which is not copied, so the first key can be on the next line
+	copied[0] = copied[0][origin.Column-1:]
+	if len(origin.Anchor) > 0 {
+		copied[0] = strings.TrimSpace(strings.TrimPrefix(copied[0], "&"+origin.Anchor))
+		if len(copied[0]) == 0 {
+			copied = copied[1:]
+			copied[0] = strings.TrimSpace(copied[0])
+		}
+	}
+
+	entry := []string{"- " + copied[0]}
+	for _, line := range copied[1:] {
+		trimmed := strings.TrimLeft(line, " ")
+		switch indent := len(line) - len(trimmed); {
+		case len(trimmed) == 0:
+			entry = append(entry, "")
+		case indent < origin.Column-1:
+			entry = append(entry, entryIndent+trimmed)
+		default:
+			entry = append(entry, entryIndent+line[origin.Column-1:])
+		}
+	}
+
+	return appendComponent(content, settings.Type, entry)
+}
+
+// deleteComponent removes the component lines, or the whole section, if it's the only component there
+func deleteComponent(content []byte, settings ComponentSettings) ([]byte, error) {
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, err
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
+		return content, nil
+	}
+
+	lines := strings.Split(string(content), "\n")
+	mapping := root.Content[0]
+	key := componentKeys[settings.Type]
+	section := getMappingValue(mapping, key)
+	if section == nil {
+		return content, nil
+	}
+
+	for _, item := range section.Content {
+
+		component := item
+		if component.Kind == yaml.AliasNode {
+			component = component.Alias
+		}
+		if title := getMappingValue(component, "title"); title == nil || title.Value != settings.Title {
+			continue
+		}
+
+		from, to := item.Line-1, getNodeEnd(item, lines)
+		if len(section.Content) == 1 {
+			for i := 0; i+1 < len(mapping.Content); i += 2 {
+				if mapping.Content[i+1] == section {
+					from = mapping.Content[i].Line - 1
+				}
+			}
+			to = getSectionEnd(mapping, section, lines)
+		} else {
+			// comment lines right above the component belong to it
+			for from > 0 && strings.HasPrefix(lines[from-1], strings.Repeat(" ", section.Column-1)+"#") {
+				from--
 			}
 		}
-	case TypeTextBox:
-		for i, component := range c.TextBoxes {
-			if component.Title == componentTitle {
-				return &c.TextBoxes[i].ComponentConfig
+
+		return []byte(strings.Join(applyEdits(lines, []lineEdit{{from: from, to: to, removed: true}}), "\n")), nil
+	}
+
+	return content, nil
+}
+
+// lineEdit replaces lines in [from, to) range, zero-based, with a single line, or removes them
+type lineEdit struct {
+	from    int
+	to      int
+	line    string
+	removed bool
+}
+
+// applyEdits applies edits from the end of the file, so line numbers of the rest stay valid
+func applyEdits(lines []string, edits []lineEdit) []string {
+
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].from > edits[j].from
+	})
+
+	for _, e := range edits {
+		updated := append([]string{}, lines[:e.from]...)
+		if !e.removed {
+			updated = append(updated, e.line)
+		}
+		lines = append(updated, lines[e.to:]...)
+	}
+
+	return lines
+}
+
+// getNodeEnd returns zero-based index of the line after the node, including content of the
+// multiline scalars, which is indented deeper than the node
+func getNodeEnd(node *yaml.Node, lines []string) int {
+
+	end := getLastLine(node)
+
+	for i := end; i < len(lines); i++ {
+		if len(strings.TrimSpace(lines[i])) == 0 {
+			continue
+		}
+		if len(lines[i])-len(strings.TrimLeft(lines[i], " ")) < node.Column {
+			break
+		}
+		end = i + 1
+	}
+
+	return end
+}
+
+var componentKeys = map[ComponentType]string{
+	TypeRunChart:  "runcharts",
+	TypeBarChart:  "barcharts",
+	TypeGauge:     "gauges",
+	TypeSparkLine: "sparklines",
+	TypeAsciiBox:  "asciiboxes",
+	TypeTextBox:   "textboxes",
+}
+
+func findComponentNode(root *yaml.Node, componentType ComponentType, componentTitle string) *yaml.Node {
+
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		if components := getMappingValue(root.Content[0], componentKeys[componentType]); components != nil {
+			for _, component := range components.Content {
+				if component.Kind == yaml.AliasNode {
+					component = component.Alias
+				}
+				if title := getMappingValue(component, "title"); title != nil && title.Value == componentTitle {
+					return component
+				}
 			}
 		}
 	}
@@ -99,30 +464,125 @@ func (c *Config) findComponent(componentType ComponentType, componentTitle strin
 		"Failed to find component type %v with title %v", componentType, componentTitle))
 }
 
-func readFile(location *string) *Config {
+func getMappingValue(mapping *yaml.Node, key string) *yaml.Node {
 
-	yamlFile, err := ioutil.ReadFile(*location)
-	if err != nil {
-		log.Fatalf("Failed to read config file: %s", *location)
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return nil
 	}
 
-	cfg := new(Config)
-	err = yaml.Unmarshal(yamlFile, cfg)
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
 
-	if err != nil {
-		log.Fatalf("Failed to read config file: %v", err)
+	return nil
+}
+
+// getPositionEdit replaces the existing position, keeping its line comment,
+// or adds position right after the component title
+func getPositionEdit(component *yaml.Node, lines []string, position string) lineEdit {
+
+	var key, value, title *yaml.Node
+	for i := 0; i+1 < len(component.Content); i += 2 {
+		switch component.Content[i].Value {
+		case "position":
+			key, value = component.Content[i], component.Content[i+1]
+		case "title":
+			title = component.Content[i]
+		}
+	}
+
+	if key != nil {
+		line := lines[key.Line-1][:key.Column-1] + "position: " + position
+		if len(value.LineComment) > 0 {
+			line += " " + value.LineComment
+		}
+		return lineEdit{from: key.Line - 1, to: getLastLine(value), line: line}
 	}
 
-	return cfg
+	indent := strings.Repeat(" ", title.Column-1)
+	return lineEdit{from: title.Line, to: title.Line, line: indent + "position: " + position}
 }
 
-func saveFile(config *Config, fileName *string) {
-	file, err := yaml.Marshal(config)
+// getBreakpointPositionEdit replaces the position in the component positions mapping, adds it to the
+// end of the mapping, or adds the mapping itself to the end of the component
+func getBreakpointPositionEdit(component *yaml.Node, lines []string, breakpoint string, position string) (lineEdit, error) {
+
+	positions := getMappingValue(component, "positions")
+
+	if positions == nil {
+		indent := strings.Repeat(" ", component.Column-1)
+		at := getNodeEnd(component, lines)
+		line := indent + "positions:\n" + indent + entryIndent + formatScalar(breakpoint) + ": " + position
+		return lineEdit{from: at, to: at, line: line}, nil
+	}
+
+	if positions.Kind != yaml.MappingNode || positions.Style&yaml.FlowStyle != 0 {
+		return lineEdit{}, fmt.Errorf("'positions' should be a block mapping to save the position for '%s'", breakpoint)
+	}
+
+	for i := 0; i+1 < len(positions.Content); i += 2 {
+		key, value := positions.Content[i], positions.Content[i+1]
+		if key.Value != breakpoint {
+			continue
+		}
+		line := lines[key.Line-1][:key.Column-1] + formatScalar(breakpoint) + ": " + position
+		if len(value.LineComment) > 0 {
+			line += " " + value.LineComment
+		}
+		return lineEdit{from: key.Line - 1, to: getLastLine(value), line: line}, nil
+	}
+
+	at := getNodeEnd(positions, lines)
+	line := strings.Repeat(" ", positions.Column-1) + formatScalar(breakpoint) + ": " + position
+	return lineEdit{from: at, to: at, line: line}, nil
+}
+
+// getLastLine returns the last line, occupied by the node and its children
+func getLastLine(node *yaml.Node) int {
+	last := node.Line
+	for _, child := range node.Content {
+		if l := getLastLine(child); l > last {
+			last = l
+		}
+	}
+	return last
+}
+
+// formatPosition returns position in a flow style, e.g. [[0, 0], [45, 20]]
+func formatPosition(location Location, size Size) string {
+	p := getPosition(location, size)
+	return fmt.Sprintf("[[%d, %d], [%d, %d]]", p[0][0], p[0][1], p[1][0], p[1][1])
+}
+
+// writeFileAtomically writes content to a temporary file, which then replaces the original one.
+// File mode of the original file is kept
+func writeFileAtomically(fileName string, content []byte) error {
+
+	info, err := os.Stat(fileName)
 	if err != nil {
-		log.Fatalf("Failed to marshal config file: %v", err)
+		return err
 	}
-	err = ioutil.WriteFile(*fileName, file, os.ModePerm)
+
+	temp, err := ioutil.TempFile(filepath.Dir(fileName), "."+filepath.Base(fileName)+".tmp")
 	if err != nil {
-		log.Fatalf("Failed to save config file: %v", err)
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err = temp.Write(content); err == nil {
+		err = temp.Sync()
 	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(temp.Name(), info.Mode().Perm())
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(temp.Name(), fileName)
 }
@@ -0,0 +1,206 @@
+package config
+
+import (
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const updateSource = `# dashboard for the on-call team
+variables:
+  token: ${env:TOKEN}
+runcharts:
+  - title: Latency # p99 only
+    position: [[0, 0], [10, 10]]
+    custom-field: kept
+    items:
+      - label: api
+        sample: curl -s localhost/latency
+sparklines:
+  - title: Memory
+    position:
+      - [0, 10]
+      - [10, 10]
+    sample: free -m
+textboxes:
+  - &box
+    title: Status
+    sample: uptime
+`
+
+func TestUpdate(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "config.yml")
+	if err := ioutil.WriteFile(file, []byte(updateSource), 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	Update([]ComponentSettings{
+		{Type: TypeRunChart, Title: "Latency", Location: Location{X: 5, Y: 6}, Size: Size{X: 20, Y: 30}},
+		{Type: TypeTextBox, Title: "Status", Location: Location{X: 25, Y: 0}, Size: Size{X: 15, Y: 10}},
+		{Type: TypeSparkLine, Title: "Memory", Location: Location{X: 0, Y: 12}, Size: Size{X: 10, Y: 10}},
+	}, Options{ConfigFile: &file})
+
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := string(content)
+
+	for _, expected := range []string{
+		"# dashboard for the on-call team",
+		"title: Latency # p99 only",
+		"position: [[5, 6], [20, 30]]",
+		"custom-field: kept",
+		"token: ${env:TOKEN}",
+		"&box",
+		"position: [[25, 0], [15, 10]]",
+		"    position: [[0, 12], [10, 10]]\n    sample: free -m",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Update() result doesn't contain %q:\n%s", expected, result)
+		}
+	}
+
+	if strings.Contains(result, "rate-ms") {
+		t.Errorf("Update() result contains default values:\n%s", result)
+	}
+
+	if strings.Index(result, "runcharts") > strings.Index(result, "textboxes") {
+		t.Errorf("Update() changed keys ordering:\n%s", result)
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0640 {
+		t.Errorf("Update() changed file mode to %v", info.Mode().Perm())
+	}
+
+	files, _ := ioutil.ReadDir(filepath.Dir(file))
+	if len(files) != 1 {
+		t.Errorf("Update() left %d files in the directory, want 1", len(files))
+	}
+}
+
+func TestUpdate_duplicateAndDelete(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "config.yml")
+	if err := ioutil.WriteFile(file, []byte(updateSource), 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	latency, status := "Latency", "Status"
+
+	Update([]ComponentSettings{
+		{Type: TypeRunChart, Title: "Latency", Location: Location{X: 0, Y: 0}, Size: Size{X: 10, Y: 10}},
+		{Type: TypeRunChart, Title: "Latency copy", Location: Location{X: 10, Y: 0}, Size: Size{X: 10, Y: 10}, CopyOf: &latency},
+		{Type: TypeTextBox, Title: "Status copy", Location: Location{X: 20, Y: 0}, Size: Size{X: 10, Y: 10}, CopyOf: &status},
+		{Type: TypeSparkLine, Title: "Memory", Deleted: true},
+	}, Options{ConfigFile: &file})
+
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := string(content)
+
+	for _, expected := range []string{
+		"        sample: curl -s localhost/latency\n" +
+			"  - title: Latency copy # p99 only\n" +
+			"    position: [[10, 0], [10, 10]]\n" +
+			"    custom-field: kept\n" +
+			"    items:\n" +
+			"      - label: api\n" +
+			"        sample: curl -s localhost/latency\n" +
+			"textboxes:\n",
+		"    sample: uptime\n" +
+			"  - title: Status copy\n" +
+			"    position: [[20, 0], [10, 10]]\n" +
+			"    sample: uptime\n",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Update() result doesn't contain %q:\n%s", expected, result)
+		}
+	}
+
+	if strings.Contains(result, "sparklines") || strings.Contains(result, "free -m") {
+		t.Errorf("Update() result contains deleted component:\n%s", result)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		t.Fatalf("Update() result is invalid: %v", err)
+	}
+	if len(cfg.RunCharts) != 2 || len(cfg.TextBoxes) != 2 || len(cfg.SparkLines) != 0 {
+		t.Errorf("Update() result contains %d runcharts, %d textboxes and %d sparklines, want 2, 2 and 0",
+			len(cfg.RunCharts), len(cfg.TextBoxes), len(cfg.SparkLines))
+	}
+}
+
+const breakpointsSource = `breakpoints:
+  narrow: 120
+  tiny: 80
+runcharts:
+  - title: Latency
+    position: [[0, 0], [40, 40]]
+    positions:
+      narrow: [[0, 0], [80, 20]] # stacked
+    items:
+      - label: api
+        sample: curl -s localhost/latency
+textboxes:
+  - title: Status
+    position: [[40, 0], [40, 40]]
+    sample: uptime
+`
+
+func TestUpdate_breakpoints(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "config.yml")
+	if err := ioutil.WriteFile(file, []byte(breakpointsSource), 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	Update([]ComponentSettings{
+		{Type: TypeRunChart, Title: "Latency", Location: Location{X: 0, Y: 0}, Size: Size{X: 40, Y: 40}},
+		{Type: TypeRunChart, Title: "Latency", Location: Location{X: 0, Y: 0}, Size: Size{X: 80, Y: 25}, Breakpoint: "narrow"},
+		{Type: TypeRunChart, Title: "Latency", Location: Location{X: 0, Y: 0}, Size: Size{X: 80, Y: 40}, Breakpoint: "tiny"},
+		{Type: TypeTextBox, Title: "Status", Location: Location{X: 40, Y: 0}, Size: Size{X: 40, Y: 40}},
+		{Type: TypeTextBox, Title: "Status", Location: Location{X: 0, Y: 25}, Size: Size{X: 80, Y: 15}, Breakpoint: "narrow"},
+		{Type: TypeTextBox, Title: "Status", Location: Location{X: 0, Y: 40}, Size: Size{X: 80, Y: 10}, Breakpoint: "tiny"},
+	}, Options{ConfigFile: &file})
+
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := string(content)
+
+	for _, expected := range []string{
+		"    positions:\n" +
+			"      narrow: [[0, 0], [80, 25]] # stacked\n" +
+			"      tiny: [[0, 0], [80, 40]]\n" +
+			"    items:\n",
+		"    sample: uptime\n" +
+			"    positions:\n" +
+			"      narrow: [[0, 25], [80, 15]]\n" +
+			"      tiny: [[0, 40], [80, 10]]\n",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Update() result doesn't contain %q:\n%s", expected, result)
+		}
+	}
+
+	cfg, problems := parse(content, "")
+	if hasErrors(problems) {
+		t.Fatalf("Update() result is invalid: %v", problems)
+	}
+	if location, size, ok := cfg.TextBoxes[0].GetPositionAt("tiny"); !ok || location != (Location{X: 0, Y: 40}) || size != (Size{X: 80, Y: 10}) {
+		t.Errorf("Update() result tiny position = %v %v, want [0 40] [80 10]", location, size)
+	}
+}
@@ -0,0 +1,284 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// ComponentDraft describes a component, created from UI
+type ComponentDraft struct {
+	Type   ComponentType
+	Title  string
+	Sample string
+	RateMs int
+}
+
+const (
+	defaultGaugeMin = "echo 0"
+	defaultGaugeMax = "echo 100"
+	entryIndent     = "  "
+)
+
+// CreateComponent places the drafted component into the largest empty space, and checks it
+// against the config file. The file itself is changed on quit, along with the other menu actions.
+// Returned config contains the new component only, with default values set
+func CreateComponent(draft ComponentDraft, existing []ComponentSettings, grid GridConfig, options Options) (*Config, error) {
+
+	location, size, err := Arrange(existing, grid)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := ioutil.ReadFile(*options.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := formatComponent(draft, formatPosition(location, size))
+	if content, err = appendComponent(content, draft.Type, entry); err != nil {
+		return nil, err
+	}
+
+	cfg, problems := parse(content, filepath.Dir(*options.ConfigFile))
+	for _, p := range problems {
+		if p.Severity == SeverityError {
+			return nil, errors.New(p.Message)
+		}
+	}
+
+	cfg.setDefaults()
+
+	return cfg.find(draft.Type, draft.Title), nil
+}
+
+// Arrange finds position for a new component in the largest empty space. Existing components
+// are never moved or resized, so an error is returned, if there is no space left
+func Arrange(existing []ComponentSettings, grid GridConfig) (Location, Size, error) {
+
+	created := &ComponentConfig{}
+	components := []*ComponentConfig{created}
+	for _, s := range existing {
+		components = append(components, &ComponentConfig{Position: getPosition(s.Location, s.Size)})
+	}
+
+	if len(existing) == 0 {
+		setSingleComponentPosition(created, grid)
+	} else {
+		arrangeIntoLargestEmptySpace(created, getLargestEmptySpaceRectangle(components, grid))
+	}
+
+	if created.GetRectangle().Empty() {
+		return Location{}, Size{}, errors.New("there is no space left, please move or resize components first")
+	}
+
+	return created.GetLocation(), created.GetSize(), nil
+}
+
+// formatComponent returns the component as a block sequence entry, e.g.
+//
+//   - title: Uptime
+//     position: [[0, 0], [20, 10]]
+//     rate-ms: 1000
+//     sample: uptime
+func formatComponent(draft ComponentDraft, position string) []string {
+
+	lines := []string{
+		"- title: " + formatScalar(draft.Title),
+		entryIndent + "position: " + position,
+		entryIndent + fmt.Sprintf("rate-ms: %d", draft.RateMs),
+	}
+
+	switch draft.Type {
+	case TypeRunChart, TypeBarChart:
+		lines = append(lines,
+			entryIndent+"items:",
+			entryIndent+"  - label: "+formatScalar(draft.Title),
+			entryIndent+"    sample: "+formatScalar(draft.Sample))
+	case TypeGauge:
+		lines = append(lines,
+			entryIndent+"cur:",
+			entryIndent+"  sample: "+formatScalar(draft.Sample),
+			entryIndent+"max:",
+			entryIndent+"  sample: "+defaultGaugeMax,
+			entryIndent+"min:",
+			entryIndent+"  sample: "+defaultGaugeMin)
+	default:
+		lines = append(lines, entryIndent+"sample: "+formatScalar(draft.Sample))
+	}
+
+	return lines
+}
+
+// formatScalar quotes the value, if it's required by yaml syntax
+func formatScalar(value string) string {
+	node := yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	out, err := yaml.Marshal(&node)
+	if err != nil {
+		return fmt.Sprintf("%q", value)
+	}
+	return strings.TrimSuffix(string(out), "\n")
+}
+
+// appendComponent inserts the entry after the last component of the same type,
+// or adds a new section to the end of the file, if there are no such components yet
+func appendComponent(content []byte, componentType ComponentType, entry []string) ([]byte, error) {
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, err
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("config file should contain a mapping")
+	}
+
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	mapping := root.Content[0]
+	key := componentKeys[componentType]
+	section := getMappingValue(mapping, key)
+
+	if section == nil {
+		result := append(lines, key+":")
+		for _, line := range entry {
+			result = append(result, entryIndent+line)
+		}
+		return []byte(strings.Join(result, "\n") + "\n"), nil
+	}
+
+	if section.Kind != yaml.SequenceNode || section.Style&yaml.FlowStyle != 0 || len(section.Content) == 0 {
+		return nil, fmt.Errorf("'%s' should be a block sequence to add a component", key)
+	}
+
+	indent := strings.Repeat(" ", section.Column-1)
+	at := getSectionEnd(mapping, section, lines)
+
+	result := append([]string{}, lines[:at]...)
+	for _, line := range entry {
+		result = append(result, indent+line)
+	}
+	result = append(result, lines[at:]...)
+
+	return []byte(strings.Join(result, "\n") + "\n"), nil
+}
+
+// getSectionEnd returns zero-based index of the line after the section. The next top level key,
+// and the blank lines and top level comments before it, don't belong to the section
+func getSectionEnd(mapping *yaml.Node, section *yaml.Node, lines []string) int {
+
+	end := len(lines)
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if line := mapping.Content[i].Line; line > section.Line && line-1 < end {
+			end = line - 1
+		}
+	}
+
+	for end > section.Line {
+		line := lines[end-1]
+		if len(strings.TrimSpace(line)) > 0 && !strings.HasPrefix(line, "#") {
+			break
+		}
+		end--
+	}
+
+	return end
+}
+
+// Duplicate returns a config, which contains a copy of the component with the new title and position.
+// Position is used for the settings breakpoint as well, so the copy is visible in the current layout
+func (c *Config) Duplicate(componentType ComponentType, title string, settings ComponentSettings) *Config {
+
+	result := c.find(componentType, title)
+	for _, component := range getComponents(result) {
+		positions := make(map[string][][]int)
+		for name, position := range component.Positions {
+			positions[name] = position
+		}
+		if len(settings.Breakpoint) > 0 {
+			positions[settings.Breakpoint] = getPosition(settings.Location, settings.Size)
+		}
+		component.Title = settings.Title
+		component.Position = getPosition(settings.Location, settings.Size)
+		component.Positions = positions
+	}
+
+	// labels of single item components are their titles
+	for i := range result.SparkLines {
+		result.SparkLines[i].Item.Label = &result.SparkLines[i].Title
+	}
+	for i := range result.AsciiBoxes {
+		result.AsciiBoxes[i].Label = &result.AsciiBoxes[i].Title
+	}
+	for i := range result.TextBoxes {
+		result.TextBoxes[i].Label = &result.TextBoxes[i].Title
+	}
+
+	return result
+}
+
+// Merge adds components of the other config
+func (c *Config) Merge(other *Config) {
+	c.RunCharts = append(c.RunCharts, other.RunCharts...)
+	c.BarCharts = append(c.BarCharts, other.BarCharts...)
+	c.Gauges = append(c.Gauges, other.Gauges...)
+	c.SparkLines = append(c.SparkLines, other.SparkLines...)
+	c.AsciiBoxes = append(c.AsciiBoxes, other.AsciiBoxes...)
+	c.TextBoxes = append(c.TextBoxes, other.TextBoxes...)
+}
+
+// Remove deletes the component from the config
+func (c *Config) Remove(componentType ComponentType, title string) {
+	*c = *c.filter(func(t ComponentType, c ComponentConfig) bool {
+		return t != componentType || c.Title != title
+	})
+}
+
+// filter returns a config, which contains the components, matching the predicate
+func (c *Config) filter(match func(ComponentType, ComponentConfig) bool) *Config {
+
+	result := *c
+	result.RunCharts, result.BarCharts, result.Gauges = nil, nil, nil
+	result.SparkLines, result.AsciiBoxes, result.TextBoxes = nil, nil, nil
+
+	for _, r := range c.RunCharts {
+		if match(TypeRunChart, r.ComponentConfig) {
+			result.RunCharts = append(result.RunCharts, r)
+		}
+	}
+	for _, b := range c.BarCharts {
+		if match(TypeBarChart, b.ComponentConfig) {
+			result.BarCharts = append(result.BarCharts, b)
+		}
+	}
+	for _, g := range c.Gauges {
+		if match(TypeGauge, g.ComponentConfig) {
+			result.Gauges = append(result.Gauges, g)
+		}
+	}
+	for _, s := range c.SparkLines {
+		if match(TypeSparkLine, s.ComponentConfig) {
+			result.SparkLines = append(result.SparkLines, s)
+		}
+	}
+	for _, a := range c.AsciiBoxes {
+		if match(TypeAsciiBox, a.ComponentConfig) {
+			result.AsciiBoxes = append(result.AsciiBoxes, a)
+		}
+	}
+	for _, t := range c.TextBoxes {
+		if match(TypeTextBox, t.ComponentConfig) {
+			result.TextBoxes = append(result.TextBoxes, t)
+		}
+	}
+
+	return &result
+}
+
+// find returns a config, which contains only the specified component
+func (c *Config) find(componentType ComponentType, title string) *Config {
+	return c.filter(func(t ComponentType, c ComponentConfig) bool {
+		return t == componentType && c.Title == title
+	})
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var defaultGrid = GridConfig{Columns: 80, Rows: 40}
+
+const createSource = `runcharts:
+  - title: Latency
+    position: [[0, 0], [80, 20]]
+    items:
+      - label: api
+        sample: |
+          curl -s localhost/latency
+
+# processes
+textboxes:
+  - title: Status
+    position: [[0, 20], [40, 20]]
+    sample: uptime
+`
+
+func TestCreateComponent(t *testing.T) {
+
+	existing := []ComponentSettings{
+		{Type: TypeRunChart, Title: "Latency", Location: Location{X: 0, Y: 0}, Size: Size{X: 80, Y: 20}},
+		{Type: TypeTextBox, Title: "Status", Location: Location{X: 0, Y: 20}, Size: Size{X: 40, Y: 20}},
+	}
+
+	tests := []struct {
+		name     string
+		draft    ComponentDraft
+		expected string
+	}{
+		{"should append to the existing section",
+			ComponentDraft{Type: TypeRunChart, Title: "Errors: 5xx", Sample: "echo 1", RateMs: 500},
+			"          curl -s localhost/latency\n" +
+				"  - title: 'Errors: 5xx'\n" +
+				"    position: [[40, 20], [40, 20]]\n" +
+				"    rate-ms: 500\n" +
+				"    items:\n" +
+				"      - label: 'Errors: 5xx'\n" +
+				"        sample: echo 1\n\n# processes\n"},
+		{"should add a new section",
+			ComponentDraft{Type: TypeGauge, Title: "CPU", Sample: "echo 42", RateMs: 1000},
+			"    sample: uptime\n" +
+				"gauges:\n" +
+				"  - title: CPU\n" +
+				"    position: [[40, 20], [40, 20]]\n" +
+				"    rate-ms: 1000\n" +
+				"    cur:\n" +
+				"      sample: echo 42\n" +
+				"    max:\n" +
+				"      sample: echo 100\n" +
+				"    min:\n" +
+				"      sample: echo 0\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			file := filepath.Join(t.TempDir(), "config.yml")
+			if err := ioutil.WriteFile(file, []byte(createSource), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := CreateComponent(tt.draft, existing, defaultGrid, Options{ConfigFile: &file})
+			if err != nil {
+				t.Fatalf("CreateComponent() error = %v", err)
+			}
+
+			components := getComponents(cfg)
+			if len(components) != 1 || components[0].Title != tt.draft.Title || *components[0].RateMs != tt.draft.RateMs {
+				t.Fatalf("CreateComponent() config = %+v, want the drafted component only", components)
+			}
+
+			if content, _ := ioutil.ReadFile(file); string(content) != createSource {
+				t.Errorf("CreateComponent() changed the file before quit:\n%s", content)
+			}
+
+			// the component is saved on quit, along with the other changes
+			Update([]ComponentSettings{{Type: tt.draft.Type, Title: tt.draft.Title,
+				Location: components[0].GetLocation(), Size: components[0].GetSize(), Draft: &tt.draft}}, Options{ConfigFile: &file})
+
+			content, _ := ioutil.ReadFile(file)
+			if !strings.Contains(string(content), tt.expected) {
+				t.Errorf("Update() result doesn't contain %q:\n%s", tt.expected, content)
+			}
+		})
+	}
+}
+
+func TestCreateComponent_noEmptySpace(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "config.yml")
+	if err := ioutil.WriteFile(file, []byte(createSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	existing := []ComponentSettings{
+		{Type: TypeRunChart, Title: "Latency", Location: Location{X: 0, Y: 0}, Size: Size{X: 80, Y: 20}},
+		{Type: TypeTextBox, Title: "Status", Location: Location{X: 0, Y: 20}, Size: Size{X: 80, Y: 20}},
+	}
+	draft := ComponentDraft{Type: TypeTextBox, Title: "Date", Sample: "date", RateMs: 1000}
+
+	// existing components are never split to free the space
+	if _, err := CreateComponent(draft, existing, defaultGrid, Options{ConfigFile: &file}); err == nil {
+		t.Errorf("CreateComponent() should fail, when there is no empty space")
+	}
+
+	if content, _ := ioutil.ReadFile(file); string(content) != createSource {
+		t.Errorf("CreateComponent() changed the file on failure:\n%s", content)
+	}
+}
+
+func TestCreateComponent_invalid(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "config.yml")
+	if err := ioutil.WriteFile(file, []byte(createSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	draft := ComponentDraft{Type: TypeTextBox, Title: "Latency", Sample: "date", RateMs: 1000}
+	if _, err := CreateComponent(draft, nil, defaultGrid, Options{ConfigFile: &file}); err == nil {
+		t.Errorf("CreateComponent() should fail on duplicate title")
+	}
+
+	content, _ := ioutil.ReadFile(file)
+	if string(content) != createSource {
+		t.Errorf("CreateComponent() changed the file on failure:\n%s", content)
+	}
+}
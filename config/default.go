@@ -5,9 +5,10 @@ import (
 )
 
 const (
-	defaultRateMs = 1000
-	defaultScale  = 1
-	defaultTheme  = console.ThemeDark
+	defaultRateMs        = 1000
+	defaultStatsdFlushMs = 1000
+	defaultScale         = 1
+	defaultTheme         = console.ThemeDark
 )
 
 func (c *Config) setDefaults() {
@@ -23,6 +24,11 @@ func (c *Config) setDefaultValues() {
 		c.Theme = &t
 	}
 
+	if c.Statsd != nil && c.Statsd.FlushMs == nil {
+		f := defaultStatsdFlushMs
+		c.Statsd.FlushMs = &f
+	}
+
 	for i, chart := range c.RunCharts {
 
 		setDefaultTriggersValues(chart.Triggers)
@@ -175,9 +181,10 @@ func setDefaultTriggersValues(triggers []TriggerConfig) {
 
 func (c *Config) setDefaultItemSettings() {
 
-	palette := console.GetPalette(*c.Theme)
+	palette := c.GetPalette()
 	colorsCount := len(palette.ContentColors)
 	defaultPty := false
+	defaultLine := LineSolid
 	defaultPercentOnly := false
 
 	for _, ch := range c.RunCharts {
@@ -185,9 +192,15 @@ func (c *Config) setDefaultItemSettings() {
 			if item.Color == nil {
 				item.Color = &palette.ContentColors[j%colorsCount]
 			}
+			if item.Line == nil {
+				item.Line = &defaultLine
+			}
 			if item.Pty == nil {
 				item.Pty = &defaultPty
 			}
+			if item.Ssh == nil {
+				item.Ssh = ch.Ssh
+			}
 			ch.Items[j] = item
 		}
 	}
@@ -200,6 +213,9 @@ func (c *Config) setDefaultItemSettings() {
 			if item.Pty == nil {
 				item.Pty = &defaultPty
 			}
+			if item.Ssh == nil {
+				item.Ssh = b.Ssh
+			}
 			b.Items[j] = item
 		}
 	}
@@ -222,6 +238,11 @@ func (c *Config) setDefaultItemSettings() {
 		if g.Cur.Pty == nil {
 			g.Cur.Pty = &defaultPty
 		}
+		for _, item := range []*Item{&g.Min, &g.Max, &g.Cur} {
+			if item.Ssh == nil {
+				item.Ssh = g.Ssh
+			}
+		}
 		if g.Color == nil {
 			g.Color = &palette.ContentColors[i%colorsCount]
 		}
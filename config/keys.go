@@ -0,0 +1,21 @@
+package config
+
+import "github.com/sqshq/sampler/console"
+
+// GetKeys returns the key bindings, where the actions missing in config keep the default keys.
+// <C-c> always quits, the same way as in the wizard, so a remapped quit can't leave no way out
+func (c *Config) GetKeys() console.KeyMap {
+
+	keys := console.GetDefaultKeyMap()
+	for action, bound := range c.Keys {
+		keys[action] = bound
+	}
+
+	if keys.GetAction(console.KeyQuit3) == "" {
+		quit := make([]string, len(keys[console.ActionQuit]), len(keys[console.ActionQuit])+1)
+		copy(quit, keys[console.ActionQuit])
+		keys[console.ActionQuit] = append(quit, console.KeyQuit3)
+	}
+
+	return keys
+}
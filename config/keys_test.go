@@ -0,0 +1,29 @@
+package config
+
+import (
+	"github.com/sqshq/sampler/console"
+	"reflect"
+	"testing"
+)
+
+func TestConfig_GetKeys(t *testing.T) {
+
+	tests := []struct {
+		name string
+		keys map[console.Action][]string
+		want []string
+	}{
+		{"should keep the default quit keys", nil, []string{"q", "Q", console.KeyQuit3}},
+		{"should add <C-c> to the remapped quit", map[console.Action][]string{console.ActionQuit: {"x"}}, []string{"x", console.KeyQuit3}},
+		{"should keep <C-c> position", map[console.Action][]string{console.ActionQuit: {console.KeyQuit3, "x"}}, []string{console.KeyQuit3, "x"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := Config{Keys: test.keys}
+			if got := cfg.GetKeys()[console.ActionQuit]; !reflect.DeepEqual(got, test.want) {
+				t.Errorf("GetKeys() quit = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
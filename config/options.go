@@ -1,8 +1,15 @@
 package config
 
+import (
+	"github.com/sqshq/sampler/console"
+	"time"
+)
+
 // Options with cli flags
 type Options struct {
-	ConfigFile  *string  `short:"c" long:"config" description:"Path to YAML config file"`
-	Environment []string `short:"e" long:"env" description:"Specify name=value variable to use in script placeholder as $name. This flag takes precedence over the same name variables, specified in config yml"`
-	Version     bool     `short:"v" long:"version" description:"Print version"`
+	ConfigFile  *string            `short:"c" long:"config" description:"Path to YAML config file"`
+	Environment []string           `short:"e" long:"env" description:"Specify name=value variable to use in script placeholder as $name. This flag takes precedence over the same name variables, specified in config yml"`
+	Version     bool               `short:"v" long:"version" description:"Print version"`
+	Color       *console.ColorMode `long:"color" description:"Color mode, detected from TERM and COLORTERM by default" choice:"never" choice:"8" choice:"16" choice:"256" choice:"truecolor"`
+	Diagnose    *time.Duration     `long:"diagnose" description:"Sample without UI for the duration, e.g. 30s, and print sampling stats of each item"`
 }
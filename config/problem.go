@@ -0,0 +1,249 @@
+package config
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Problem is a config validation issue, located in the config file. Errors prevent
+// the config from being loaded, while warnings are reported by the validate command only
+type Problem struct {
+	Line     int
+	Column   int
+	Severity Severity
+	Message  string
+}
+
+// Format returns the problem in the file:line:column: severity: message form
+func (p Problem) Format(file string) string {
+	if p.Line == 0 {
+		return fmt.Sprintf("%s: %s: %s", file, p.Severity, p.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s: %s", file, p.Line, p.Column, p.Severity, p.Message)
+}
+
+// path addresses a node in the config tree by mapping keys and sequence indexes
+type path []interface{}
+
+func (p path) with(elements ...interface{}) path {
+	return append(append(path{}, p...), elements...)
+}
+
+// validator collects all the problems, instead of failing on the first one
+type validator struct {
+	root     *yaml.Node
+	problems []Problem
+}
+
+func (v *validator) errorf(p path, format string, args ...interface{}) {
+	v.report(p, SeverityError, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) warnf(p path, format string, args ...interface{}) {
+	v.report(p, SeverityWarning, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) report(p path, severity Severity, message string) {
+	line, column := v.locate(p)
+	v.problems = append(v.problems, Problem{Line: line, Column: column, Severity: severity, Message: message})
+}
+
+func (v *validator) sortedProblems() []Problem {
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		if v.problems[i].Line != v.problems[j].Line {
+			return v.problems[i].Line < v.problems[j].Line
+		}
+		return v.problems[i].Column < v.problems[j].Column
+	})
+	return v.problems
+}
+
+// locate returns position of the node, or of its closest parent, if the node doesn't exist
+func (v *validator) locate(p path) (int, int) {
+
+	if v.root == nil || len(v.root.Content) == 0 {
+		return 0, 0
+	}
+
+	node := v.root.Content[0]
+
+	for _, element := range p {
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+		var next *yaml.Node
+		switch e := element.(type) {
+		case string:
+			next = getMappingValue(node, e)
+		case int:
+			if node.Kind == yaml.SequenceNode && e < len(node.Content) {
+				next = node.Content[e]
+			}
+		}
+		if next == nil {
+			break
+		}
+		node = next
+	}
+
+	return node.Line, node.Column
+}
+
+var yamlErrorPattern = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// reportYamlError converts parsing and decoding errors into problems
+func (v *validator) reportYamlError(err error) {
+
+	messages := []string{err.Error()}
+	if typeErr, ok := err.(*yaml.TypeError); ok {
+		messages = typeErr.Errors
+	}
+
+	for _, message := range messages {
+		problem := Problem{Severity: SeverityError, Message: message}
+		if match := yamlErrorPattern.FindStringSubmatch(message); match != nil {
+			problem.Line, _ = strconv.Atoi(match[1])
+			problem.Column = 1
+			problem.Message = match[2]
+		}
+		v.problems = append(v.problems, problem)
+	}
+}
+
+// validateKeys reports mapping keys, which don't match any field of the config structures
+func (v *validator) validateKeys(node *yaml.Node, t reflect.Type, severity Severity) {
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if node.Kind == yaml.DocumentNode || node.Kind == yaml.AliasNode {
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+		for _, child := range node.Content {
+			v.validateKeys(child, t, severity)
+		}
+		return
+	}
+
+	switch {
+	case t.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		fields := getYamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "<<" {
+				v.validateKeys(value, t, severity)
+				continue
+			}
+			field, ok := fields[key.Value]
+			if !ok {
+				message := fmt.Sprintf("unknown key '%s'", key.Value)
+				if suggestion := suggestKey(key.Value, fields); len(suggestion) > 0 {
+					message += fmt.Sprintf(", did you mean '%s'?", suggestion)
+				}
+				v.problems = append(v.problems, Problem{Line: key.Line, Column: key.Column, Severity: severity, Message: message})
+				continue
+			}
+			v.validateKeys(value, field, severity)
+		}
+	case t.Kind() == reflect.Slice && node.Kind == yaml.SequenceNode:
+		for _, child := range node.Content {
+			v.validateKeys(child, t.Elem(), severity)
+		}
+	case t.Kind() == reflect.Map && node.Kind == yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			v.validateKeys(node.Content[i], t.Elem(), severity)
+		}
+	}
+}
+
+// getYamlFields returns field types by yaml keys, including the inlined structures
+func getYamlFields(t reflect.Type) map[string]reflect.Type {
+
+	fields := make(map[string]reflect.Type)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("yaml"), ",")
+		if tag[0] == "-" {
+			continue
+		}
+		if len(tag) > 1 && tag[1] == "inline" {
+			for name, inlined := range getYamlFields(field.Type) {
+				fields[name] = inlined
+			}
+			continue
+		}
+		name := tag[0]
+		if len(name) == 0 {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+	}
+
+	return fields
+}
+
+// suggestKey returns a known key, which is the most similar to the mistyped one
+func suggestKey(key string, fields map[string]reflect.Type) string {
+
+	normalized := strings.ToLower(strings.NewReplacer("_", "-", " ", "-").Replace(key))
+	suggestion, bestDistance := "", maxSuggestionDistance+1
+
+	for name := range fields {
+		if name == normalized {
+			return name
+		}
+		if d := getDistance(normalized, name); d < bestDistance || (d == bestDistance && name < suggestion) {
+			suggestion, bestDistance = name, d
+		}
+	}
+
+	return suggestion
+}
+
+const maxSuggestionDistance = 2
+
+// getDistance returns Levenshtein distance between the strings
+func getDistance(a string, b string) int {
+
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(minInt(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// PrometheusQuery selects series by name and label matchers, see ParsePrometheusQuery
+type PrometheusQuery struct {
+	Name     string
+	Matchers []PrometheusMatcher
+	Rate     bool
+}
+
+type PrometheusMatcher struct {
+	Label    string
+	Operator string
+	Value    string
+	Regex    *regexp.Regexp
+}
+
+// ParsePrometheusQuery parses "metric", "metric{label="value",...}" or "rate(...)" of them.
+// Label matchers support =, !=, =~ and !~ operators, as in PromQL
+func ParsePrometheusQuery(query string) (PrometheusQuery, error) {
+
+	var q PrometheusQuery
+	selector := strings.TrimSpace(query)
+
+	if strings.HasPrefix(selector, "rate(") {
+		if !strings.HasSuffix(selector, ")") {
+			return PrometheusQuery{}, fmt.Errorf("closing parenthesis is missing")
+		}
+		q.Rate = true
+		selector = strings.TrimSpace(selector[len("rate(") : len(selector)-1])
+	}
+
+	end := strings.Index(selector, "{")
+	if end < 0 {
+		end = len(selector)
+	}
+
+	q.Name = strings.TrimSpace(selector[:end])
+	if !prometheusNamePattern.MatchString(q.Name) {
+		return PrometheusQuery{}, fmt.Errorf("invalid metric name '%s'", q.Name)
+	}
+
+	if end == len(selector) {
+		return q, nil
+	}
+
+	labels, remaining, err := ParsePrometheusLabels(selector[end+1:])
+	if err != nil {
+		return PrometheusQuery{}, err
+	}
+	if len(strings.TrimSpace(remaining)) > 0 {
+		return PrometheusQuery{}, fmt.Errorf("unexpected '%s'", strings.TrimSpace(remaining))
+	}
+
+	for _, l := range labels {
+		m := PrometheusMatcher{Label: l[0], Operator: l[1], Value: l[2]}
+		if m.Operator == "=~" || m.Operator == "!~" {
+			// regex matchers are fully anchored in PromQL
+			if m.Regex, err = regexp.Compile("^(?:" + m.Value + ")$"); err != nil {
+				return PrometheusQuery{}, err
+			}
+		}
+		q.Matchers = append(q.Matchers, m)
+	}
+
+	return q, nil
+}
+
+var (
+	prometheusNamePattern      = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	prometheusLabelPattern     = regexp.MustCompile(`^\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*(=~|!~|!=|=)\s*"`)
+	prometheusLabelEscapes     = strings.NewReplacer(`\\`, `\`, `\"`, `"`, `\n`, "\n")
+	prometheusSeparatorPattern = regexp.MustCompile(`^\s*,?\s*`)
+)
+
+// ParsePrometheusLabels parses a comma separated list of name, operator and quoted value triples
+// until the closing brace, and returns the text after it
+func ParsePrometheusLabels(text string) ([][3]string, string, error) {
+
+	var labels [][3]string
+
+	for {
+		text = prometheusSeparatorPattern.ReplaceAllString(text, "")
+
+		if strings.HasPrefix(text, "}") {
+			return labels, text[1:], nil
+		}
+
+		match := prometheusLabelPattern.FindStringSubmatch(text)
+		if match == nil {
+			return nil, "", fmt.Errorf("invalid label near '%.20s'", text)
+		}
+		text = text[len(match[0]):]
+
+		end := -1
+		for i := 0; i < len(text); i++ {
+			if text[i] == '\\' {
+				i++
+			} else if text[i] == '"' {
+				end = i
+				break
+			}
+		}
+		if end < 0 {
+			return nil, "", fmt.Errorf("unterminated label value")
+		}
+
+		labels = append(labels, [3]string{match[1], match[2], prometheusLabelEscapes.Replace(text[:end])})
+		text = text[end+1:]
+	}
+}
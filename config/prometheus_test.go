@@ -0,0 +1,24 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestParsePrometheusQuery(t *testing.T) {
+
+	q, err := ParsePrometheusQuery(` rate(http_requests_total{code=~"5..", method!="post"}) `)
+	if err != nil {
+		t.Fatalf("ParsePrometheusQuery() error = %v", err)
+	}
+	if q.Name != "http_requests_total" || !q.Rate || len(q.Matchers) != 2 || q.Matchers[0].Regex == nil || q.Matchers[1].Value != "post" {
+		t.Errorf("ParsePrometheusQuery() = %+v", q)
+	}
+}
+
+func TestParsePrometheusQuery_invalid(t *testing.T) {
+	for _, query := range []string{`rate(up`, `1up`, `up{code=200}`, `up{code="200"`, `up{code=~"("}`, `up{code="1"} x`} {
+		if _, err := ParsePrometheusQuery(query); err == nil {
+			t.Errorf("ParsePrometheusQuery(%q) expected an error", query)
+		}
+	}
+}
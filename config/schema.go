@@ -0,0 +1,206 @@
+package config
+
+import (
+	"encoding/json"
+	ui "github.com/gizak/termui/v3"
+	"github.com/sqshq/sampler/console"
+	"reflect"
+	"strings"
+)
+
+type schema map[string]interface{}
+
+const schemaCommand = "schema"
+
+// schemaEnums lists allowed values of the string types
+var schemaEnums = map[reflect.Type][]interface{}{
+	reflect.TypeOf(console.AsciiFont2D): {console.AsciiFont2D, console.AsciiFont3D},
+	reflect.TypeOf(SqlRowsTable):        {SqlRowsTable, SqlRowsBars},
+	reflect.TypeOf(SqlDriverPostgres):   toInterfaces(reflect.ValueOf(SqlDrivers)),
+	reflect.TypeOf(UnitBytesToKiB):      toInterfaces(reflect.ValueOf(TransformUnits)),
+	reflect.TypeOf(LayoutAuto):          {LayoutManual, LayoutAuto},
+	reflect.TypeOf(TilingGrid):          toInterfaces(reflect.ValueOf(Tilings)),
+	reflect.TypeOf(LineSolid):           toInterfaces(reflect.ValueOf(LineStyles)),
+	reflect.TypeOf(console.ActionQuit):  toInterfaces(reflect.ValueOf(console.Actions)),
+}
+
+// schemaRequired lists keys, which can't be omitted. Keys of the inlined structures are inherited
+var schemaRequired = map[reflect.Type][]string{
+	reflect.TypeOf(ComponentConfig{}):  {"title"},
+	reflect.TypeOf(TriggerConfig{}):    {"title", "condition"},
+	reflect.TypeOf(GaugeConfig{}):      {"cur", "max", "min"},
+	reflect.TypeOf(StatsdConfig{}):     {"addr"},
+	reflect.TypeOf(PrometheusConfig{}): {"url", "query"},
+	reflect.TypeOf(SqlConfig{}):        {"driver", "dsn", "query"},
+}
+
+var schemaDescriptions = map[string]string{
+	"theme":              "Color theme: dark, light, dark-colorblind, light-colorblind, or one of the themes",
+	"themes":             "Custom color themes by name. Colors are xterm-256 numbers, '#rrggbb' or 'rgb(r, g, b)' values",
+	"extends":            "Built-in theme, which the missing colors are taken from. Default = dark",
+	"line":               "Run chart line pattern, so lines can be told apart without color",
+	"marker":             "Run chart symbol, drawn at the sample points and in the legend",
+	"content-colors":     "Colors of the items, used in order",
+	"gradient-colors":    "Gradients of the sparklines",
+	"base-color":         "Text and border color",
+	"medium-color":       "Color of the secondary elements, e.g. chart grid",
+	"reverse-color":      "Background color",
+	"menu-color":         "Status bar text color",
+	"menu-color-reverse": "Status bar background color",
+	"layout":             "Use positions from the config, or tile the components automatically",
+	"tiling":             "How the grid is split between the components in auto layout, or on TILE menu action",
+	"weight":             "Component share of the grid in treemap tiling, default = 1",
+	"grid":               "Logical grid size, which positions refer to. 80x40 by default",
+	"columns":            "Number of the grid columns",
+	"rows":               "Number of the grid rows",
+	"breakpoints":        "Max terminal width in columns by breakpoint name. Positions of the smallest fitting breakpoint are used",
+	"positions":          "Positions by breakpoint name, which replace the default position on narrow terminals",
+	"keys":               "Keys by action, e.g. quit: [x]. Missing actions keep the default keys, and <C-c> always quits",
+	"variables":          "Variables, available in scripts as $name",
+	"runcharts":          "Line charts with multiple items",
+	"barcharts":          "Bar charts with multiple items",
+	"gauges":             "Progress bars with min, max and current values",
+	"sparklines":         "Sparklines with a single item",
+	"textboxes":          "Text output of a script",
+	"asciiboxes":         "Text output of a script in ASCII art font",
+	"title":              "Title, unique across the components",
+	"position":           "[[x, y], [width, height]] on the configured grid (80x40 by default). Omit to arrange automatically",
+	"rate-ms":            "Sampling rate in milliseconds",
+	"timeout-ms":         "Max time in milliseconds to wait for a command completion. Default = rate-ms for samples, 30 seconds for init steps",
+	"source":             "Push source, instead of sampling with scripts",
+	"triggers":           "Conditional alerts",
+	"type":               "Component type, set by sampler",
+	"label":              "Item label, unique within the component",
+	"color":              "Color from the xterm-256 palette",
+	"sample":             "Script, which outputs the sample value",
+	"init":               "Script, which starts an interactive shell for the sample script",
+	"multistep-init":     "Scripts, which are executed one by one in an interactive shell",
+	"transform":          "Script, which transforms the sample value, available as $sample",
+	"transforms":         "Transformation steps, applied to the sample value in order",
+	"pty":                "Run interactive shell in PTY mode",
+	"ssh":                "SSH destination to run the scripts on",
+	"condition":          "Script, which outputs 1 or true to fire the trigger. Values are available as $prev and $cur",
+}
+
+var (
+	colorType      = reflect.TypeOf(ui.Color(0))
+	themeColorType = reflect.TypeOf(ThemeColor(""))
+)
+
+// Schema generates JSON Schema of the config file from the config structures,
+// so editors with YAML language server can validate and autocomplete it
+func Schema() ([]byte, error) {
+
+	definitions := make(map[string]schema)
+	root := getObjectSchema(reflect.TypeOf(Config{}), definitions)
+
+	root["$schema"] = "http://json-schema.org/draft-07/schema#"
+	root["title"] = "Sampler config"
+	root["definitions"] = definitions
+
+	return json.MarshalIndent(root, "", "  ")
+}
+
+func getSchema(t reflect.Type, definitions map[string]schema) schema {
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if enum, ok := schemaEnums[t]; ok {
+		return schema{"type": "string", "enum": enum}
+	}
+
+	if t == colorType {
+		return schema{"type": "integer", "minimum": minColor, "maximum": maxColor}
+	}
+
+	if t == themeColorType {
+		return schema{"type": []string{"integer", "string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return schema{"type": "string"}
+	case reflect.Bool:
+		return schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return schema{"type": "number"}
+	case reflect.Slice:
+		return schema{"type": "array", "items": getSchema(t.Elem(), definitions)}
+	case reflect.Map:
+		s := schema{"type": "object", "additionalProperties": getSchema(t.Elem(), definitions)}
+		if enum, ok := schemaEnums[t.Key()]; ok {
+			s["propertyNames"] = schema{"enum": enum}
+		}
+		return s
+	case reflect.Struct:
+		if _, ok := definitions[t.Name()]; !ok {
+			definitions[t.Name()] = nil // prevents infinite recursion
+			definitions[t.Name()] = getObjectSchema(t, definitions)
+		}
+		return schema{"$ref": "#/definitions/" + t.Name()}
+	}
+
+	return schema{}
+}
+
+// getObjectSchema describes the structure, forbidding unknown keys the same way config loading does
+func getObjectSchema(t reflect.Type, definitions map[string]schema) schema {
+
+	properties := make(map[string]schema)
+	required := getSchemaProperties(t, properties, definitions)
+
+	result := schema{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		result["required"] = required
+	}
+
+	return result
+}
+
+func getSchemaProperties(t reflect.Type, properties map[string]schema, definitions map[string]schema) []string {
+
+	required := append([]string{}, schemaRequired[t]...)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("yaml"), ",")
+		if tag[0] == "-" {
+			continue
+		}
+		if len(tag) > 1 && tag[1] == "inline" {
+			required = append(required, getSchemaProperties(field.Type, properties, definitions)...)
+			continue
+		}
+		name := tag[0]
+		if len(name) == 0 {
+			name = strings.ToLower(field.Name)
+		}
+		property := getSchema(field.Type, definitions)
+		if description, ok := schemaDescriptions[name]; ok {
+			if _, ref := property["$ref"]; ref {
+				// keywords next to $ref are ignored in draft-07
+				property = schema{"allOf": []schema{property}}
+			}
+			property["description"] = description
+		}
+		properties[name] = property
+	}
+
+	return required
+}
+
+func toInterfaces(values reflect.Value) []interface{} {
+	result := make([]interface{}, values.Len())
+	for i := range result {
+		result[i] = values.Index(i).Interface()
+	}
+	return result
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+
+	content, err := Schema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var root map[string]interface{}
+	if err := json.Unmarshal(content, &root); err != nil {
+		t.Fatalf("Schema() returned invalid JSON: %v", err)
+	}
+
+	if root["additionalProperties"] != false {
+		t.Errorf("Schema() allows unknown top-level keys")
+	}
+
+	definitions := root["definitions"].(map[string]interface{})
+
+	tests := []struct {
+		name string
+		typ  reflect.Type
+	}{
+		{"RunChartConfig", reflect.TypeOf(RunChartConfig{})},
+		{"SparkLineConfig", reflect.TypeOf(SparkLineConfig{})},
+		{"GaugeConfig", reflect.TypeOf(GaugeConfig{})},
+		{"Item", reflect.TypeOf(Item{})},
+		{"TriggerConfig", reflect.TypeOf(TriggerConfig{})},
+		{"TransformConfig", reflect.TypeOf(TransformConfig{})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			definition, ok := definitions[tt.name].(map[string]interface{})
+			if !ok {
+				t.Fatalf("Schema() doesn't define %s", tt.name)
+			}
+			properties := definition["properties"].(map[string]interface{})
+			for key := range getYamlFields(tt.typ) {
+				if _, ok := properties[key]; !ok {
+					t.Errorf("Schema() %s doesn't contain '%s' key", tt.name, key)
+				}
+			}
+			if len(properties) != len(getYamlFields(tt.typ)) {
+				t.Errorf("Schema() %s contains %d keys, want %d", tt.name, len(properties), len(getYamlFields(tt.typ)))
+			}
+		})
+	}
+
+	unit := definitions["TransformConfig"].(map[string]interface{})["properties"].(map[string]interface{})["unit"]
+	if enum := unit.(map[string]interface{})["enum"].([]interface{}); len(enum) != len(TransformUnits) {
+		t.Errorf("Schema() unit enum = %v, want %v", enum, TransformUnits)
+	}
+}
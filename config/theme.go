@@ -0,0 +1,152 @@
+package config
+
+import (
+	"bytes"
+	ui "github.com/gizak/termui/v3"
+	"github.com/sqshq/sampler/console"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"path/filepath"
+)
+
+// ThemeConfig describes a custom color theme. Colors, which are not specified,
+// are taken from the built-in theme it extends, or from the theme file
+type ThemeConfig struct {
+	File             *string        `yaml:"file,omitempty"`
+	Extends          *console.Theme `yaml:"extends,omitempty"`
+	ContentColors    []ThemeColor   `yaml:"content-colors,omitempty"`
+	GradientColors   [][]ThemeColor `yaml:"gradient-colors,omitempty"`
+	BaseColor        *ThemeColor    `yaml:"base-color,omitempty"`
+	MediumColor      *ThemeColor    `yaml:"medium-color,omitempty"`
+	ReverseColor     *ThemeColor    `yaml:"reverse-color,omitempty"`
+	MenuColor        *ThemeColor    `yaml:"menu-color,omitempty"`
+	MenuColorReverse *ThemeColor    `yaml:"menu-color-reverse,omitempty"`
+}
+
+// ThemeColor is xterm-256 color number, #rrggbb or rgb(r, g, b) value
+type ThemeColor string
+
+// GetPalette returns the palette of the built-in or custom theme
+func (c *Config) GetPalette() console.Palette {
+
+	if theme, ok := c.Themes[string(*c.Theme)]; ok {
+		return theme.getPalette()
+	}
+
+	return console.GetPalette(*c.Theme)
+}
+
+func (t ThemeConfig) getPalette() console.Palette {
+
+	palette := console.GetPalette(console.ThemeDark)
+	if t.Extends != nil {
+		palette = console.GetPalette(*t.Extends)
+	}
+
+	if len(t.ContentColors) > 0 {
+		palette.ContentColors = toColors(t.ContentColors)
+	}
+	if len(t.GradientColors) > 0 {
+		palette.GradientColors = nil
+		for _, gradient := range t.GradientColors {
+			palette.GradientColors = append(palette.GradientColors, toColors(gradient))
+		}
+	}
+
+	for _, c := range []struct {
+		color  *ThemeColor
+		target *ui.Color
+	}{
+		{t.BaseColor, &palette.BaseColor},
+		{t.MediumColor, &palette.MediumColor},
+		{t.ReverseColor, &palette.ReverseColor},
+		{t.MenuColor, &palette.MenuColor},
+		{t.MenuColorReverse, &palette.MenuColorReverse},
+	} {
+		if c.color != nil {
+			*c.target, _ = console.ParseColor(string(*c.color))
+		}
+	}
+
+	return palette
+}
+
+// toColors maps the theme colors to the palette. Invalid colors are reported by config validation
+func toColors(colors []ThemeColor) []ui.Color {
+	result := make([]ui.Color, len(colors))
+	for i, c := range colors {
+		result[i], _ = console.ParseColor(string(c))
+	}
+	return result
+}
+
+// loadThemeFiles reads the colors, which are not specified in the config, from the theme files.
+// Relative paths are resolved against the config file directory, rather than the working one
+func (c *Config) loadThemeFiles(v *validator, dir string) {
+
+	for name, theme := range c.Themes {
+
+		if theme.File == nil {
+			continue
+		}
+
+		p := path{"themes", name, "file"}
+
+		file := *theme.File
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			v.errorf(p, "failed to read theme file: %v", err)
+			continue
+		}
+
+		var loaded ThemeConfig
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		if err = decoder.Decode(&loaded); err != nil {
+			v.errorf(p, "failed to parse theme file %s: %v", *theme.File, err)
+			continue
+		}
+
+		if loaded.File != nil {
+			v.errorf(p, "theme file %s should not refer to another file", *theme.File)
+			continue
+		}
+
+		c.Themes[name] = theme.merge(loaded)
+	}
+}
+
+// merge returns the theme, which colors are complemented by the other theme
+func (t ThemeConfig) merge(other ThemeConfig) ThemeConfig {
+
+	if t.Extends == nil {
+		t.Extends = other.Extends
+	}
+	if t.ContentColors == nil {
+		t.ContentColors = other.ContentColors
+	}
+	if t.GradientColors == nil {
+		t.GradientColors = other.GradientColors
+	}
+	if t.BaseColor == nil {
+		t.BaseColor = other.BaseColor
+	}
+	if t.MediumColor == nil {
+		t.MediumColor = other.MediumColor
+	}
+	if t.ReverseColor == nil {
+		t.ReverseColor = other.ReverseColor
+	}
+	if t.MenuColor == nil {
+		t.MenuColor = other.MenuColor
+	}
+	if t.MenuColorReverse == nil {
+		t.MenuColorReverse = other.MenuColorReverse
+	}
+
+	return t
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	ui "github.com/gizak/termui/v3"
+	"github.com/sqshq/sampler/console"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const themesSource = `theme: %s
+themes:
+  contrast:
+    extends: light
+    base-color: 16
+    reverse-color: '#ffffff'
+    content-colors: ['rgb(215, 0, 0)', 21]
+  solarized:
+    file: %s
+    base-color: '#93a1a1'
+textboxes:
+  - title: Status
+    sample: uptime
+`
+
+const themeFileSource = `base-color: '#839496'
+reverse-color: '#002b36'
+gradient-colors:
+  - ['#268bd2', '#2aa198']
+`
+
+func TestConfig_GetPalette(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "solarized.yml")
+	if err := ioutil.WriteFile(file, []byte(themeFileSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	light := console.GetPalette(console.ThemeLight)
+	dark := console.GetPalette(console.ThemeDark)
+
+	contrast := light
+	contrast.BaseColor, contrast.ReverseColor = 16, console.NewRGBColor(0xff, 0xff, 0xff)
+	contrast.ContentColors = []ui.Color{console.NewRGBColor(215, 0, 0), 21}
+
+	// config colors take precedence over the theme file ones
+	solarized := dark
+	solarized.BaseColor, solarized.ReverseColor = console.NewRGBColor(0x93, 0xa1, 0xa1), console.NewRGBColor(0x00, 0x2b, 0x36)
+	solarized.GradientColors = [][]ui.Color{{console.NewRGBColor(0x26, 0x8b, 0xd2), console.NewRGBColor(0x2a, 0xa1, 0x98)}}
+
+	tests := []struct {
+		theme string
+		want  console.Palette
+	}{
+		{"light", light},
+		{"contrast", contrast},
+		{"solarized", solarized},
+	}
+
+	for _, test := range tests {
+		cfg, problems := parse([]byte(fmt.Sprintf(themesSource, test.theme, file)), "")
+		if len(problems) > 0 {
+			t.Fatalf("parse() problems = %v", problems)
+		}
+		if got := cfg.GetPalette(); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("GetPalette() of '%s' = %+v, want %+v", test.theme, got, test.want)
+		}
+	}
+}
+
+func TestConfig_loadThemeFiles_relative(t *testing.T) {
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "themes"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "themes", "solarized.yml"), []byte(themeFileSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// theme file is found next to the config file, regardless of the working directory
+	file := filepath.Join(dir, "config.yml")
+	if err := ioutil.WriteFile(file, []byte(fmt.Sprintf(themesSource, "solarized", "themes/solarized.yml")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, problems := load(file)
+	if len(problems) > 0 {
+		t.Fatalf("load() problems = %v", problems)
+	}
+	if got, want := cfg.GetPalette().ReverseColor, console.NewRGBColor(0x00, 0x2b, 0x36); got != want {
+		t.Errorf("GetPalette() reverse color = %v, want %v from the theme file", got, want)
+	}
+}
@@ -1,78 +1,532 @@
 package config
 
 import (
-	"fmt"
+	ui "github.com/gizak/termui/v3"
 	"github.com/sqshq/sampler/console"
+	"image"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
 )
 
-func (c *Config) validate() {
+const (
+	minColor = ui.ColorClear
+	maxColor = 255
+)
+
+type componentRef struct {
+	config ComponentConfig
+	path   path
+}
+
+type itemRef struct {
+	item      Item
+	component ComponentConfig
+	path      path
+	barchart  bool
+}
+
+func (c *Config) validate(v *validator) {
 
-	var components []ComponentConfig
+	components, items := getRefs(c)
 
-	for _, c := range c.RunCharts {
-		components = append(components, c.ComponentConfig)
-		validateLabelsUniqueness(c.Title, c.Items)
-		validateItemsScripts(c.Title, c.Items)
+	if len(components) == 0 {
+		v.errorf(path{}, "config should contain at least one component")
 	}
-	for _, c := range c.BarCharts {
-		components = append(components, c.ComponentConfig)
-		validateLabelsUniqueness(c.Title, c.Items)
-		validateItemsScripts(c.Title, c.Items)
+
+	grid := c.GetGrid()
+	v.validateLayout(c)
+
+	for _, ref := range components {
+		v.validateSource(ref)
+		v.validatePosition(ref, grid)
+		v.validateBreakpointPositions(ref, c.Breakpoints, grid)
+		v.validateWeight(ref)
+	}
+	for _, i := range items {
+		v.validateItem(i)
 	}
-	for _, c := range c.SparkLines {
-		components = append(components, c.ComponentConfig)
-		validateItemScripts(c.Title, c.Item)
+
+	// labels are checked for the item lists only. Sparklines, gauges and boxes
+	// have a single item per value, which is labeled by the title or by default
+	for i, c := range c.RunCharts {
+		v.validateLabels(c.Title, path{"runcharts", i}, c.Items)
+		v.validateLines(c.Title, path{"runcharts", i}, c.Items)
 	}
-	for _, c := range c.Gauges {
-		components = append(components, c.ComponentConfig)
-		validateItemScripts(c.Title, c.Min)
-		validateItemScripts(c.Title, c.Max)
-		validateItemScripts(c.Title, c.Cur)
+	for i, c := range c.BarCharts {
+		v.validateLabels(c.Title, path{"barcharts", i}, c.Items)
 	}
-	for _, c := range c.AsciiBoxes {
-		components = append(components, c.ComponentConfig)
-		validateItemScripts(c.Title, c.Item)
+	for i, c := range c.Gauges {
+		v.validateColor(c.Color, path{"gauges", i, "color"})
 	}
-	for _, c := range c.TextBoxes {
-		components = append(components, c.ComponentConfig)
-		validateItemScripts(c.Title, c.Item)
+	for i, c := range c.SparkLines {
+		if c.Gradient != nil {
+			for j := range *c.Gradient {
+				v.validateColor(&(*c.Gradient)[j], path{"sparklines", i, "gradient", j})
+			}
+		}
+	}
+	for i, c := range c.AsciiBoxes {
+		if c.Font != nil && *c.Font != console.AsciiFont2D && *c.Font != console.AsciiFont3D {
+			v.errorf(path{"asciiboxes", i, "font"}, "unknown font '%s' for '%s'. Use '%s' or '%s'",
+				*c.Font, c.Title, console.AsciiFont2D, console.AsciiFont3D)
+		}
 	}
 
-	validateTitlesUniqueness(components)
-}
+	v.validateThemes(c)
+	v.validateKeyBindings(c)
 
-func validateItemsScripts(title string, items []Item) {
-	for _, i := range items {
-		validateItemScripts(title, i)
+	v.validateTitlesUniqueness(components)
+	if !c.IsAutoLayout() {
+		v.validateOverlaps(components)
 	}
+	v.validateStatsd(c, items)
 }
 
-func validateItemScripts(title string, i Item) {
+func (v *validator) validateItem(ref itemRef) {
+
+	i, p, title := ref.item, ref.path, ref.component.Title
+
 	if i.InitScript != nil && i.MultiStepInitScript != nil {
-		console.Exit(fmt.Sprintf("Config validation error: both init and multistep-init scripts are not allowed for '%s'", title))
+		v.errorf(p, "both init and multistep-init scripts are not allowed for '%s'", title)
 	}
-	if i.SampleScript == nil {
-		console.Exit(fmt.Sprintf("Config validation error: sample script should be specified for '%s'", title))
+	if ref.component.Source != nil {
+		if i.SampleScript != nil || i.InitScript != nil || i.MultiStepInitScript != nil {
+			v.errorf(p, "sample and init scripts are not allowed along with the push source for '%s'", title)
+		}
+	} else if i.Statsd != nil || i.Prometheus != nil || i.Sql != nil {
+		if (i.Statsd != nil && i.Prometheus != nil) || (i.Sql != nil && (i.Statsd != nil || i.Prometheus != nil)) {
+			v.errorf(p, "only one of statsd, prometheus and sql sources is allowed for '%s'", title)
+		}
+		if i.SampleScript != nil || i.InitScript != nil || i.MultiStepInitScript != nil {
+			v.errorf(p, "sample and init scripts are not allowed along with the metric for '%s'", title)
+		}
+		if i.Prometheus != nil && (len(i.Prometheus.Url) == 0 || len(i.Prometheus.Query) == 0) {
+			v.errorf(p.with("prometheus"), "prometheus url and query should be specified for '%s'", title)
+		} else if i.Prometheus != nil {
+			if _, err := ParsePrometheusQuery(i.Prometheus.Query); err != nil {
+				v.errorf(p.with("prometheus", "query"), "invalid prometheus query '%s' for '%s': %v", i.Prometheus.Query, title, err)
+			}
+		}
+		if i.Sql != nil {
+			v.validateSql(title, p.with("sql"), *i.Sql)
+		}
+	} else if i.SampleScript == nil {
+		v.errorf(p, "sample script should be specified for '%s'", title)
 	}
+	for j, t := range i.Transforms {
+		v.validateTransform(title, p.with("transforms", j), t)
+	}
+	if i.Completion != nil {
+		v.validateCompletion(title, p, i)
+	}
+	if i.Sql != nil && i.Sql.Rows != nil && *i.Sql.Rows == SqlRowsBars && !ref.barchart {
+		v.errorf(p.with("sql", "rows"), "sql rows '%s' are allowed only for barcharts, found in '%s'", SqlRowsBars, title)
+	}
+	v.validateColor(i.Color, p.with("color"))
 }
 
-func validateLabelsUniqueness(title string, items []Item) {
-	labels := make(map[string]bool)
+func (v *validator) validateSql(title string, p path, sql SqlConfig) {
+
+	supported := false
+	for _, d := range SqlDrivers {
+		if sql.Driver == d {
+			supported = true
+		}
+	}
+	if !supported {
+		v.errorf(p.with("driver"), "unsupported sql driver '%s' for '%s'. Supported drivers: %v", sql.Driver, title, SqlDrivers)
+	}
+	if len(sql.Dsn) == 0 || len(sql.Query) == 0 {
+		v.errorf(p, "sql dsn and query should be specified for '%s'", title)
+	}
+	if sql.Rows != nil && *sql.Rows != SqlRowsTable && *sql.Rows != SqlRowsBars {
+		v.errorf(p.with("rows"), "unknown sql rows '%s' for '%s'. Use '%s' or '%s'", *sql.Rows, title, SqlRowsTable, SqlRowsBars)
+	}
+}
+
+func (v *validator) validateCompletion(title string, p path, i Item) {
+	p = p.with("completion")
+	if i.InitScript == nil && i.MultiStepInitScript == nil {
+		v.errorf(p, "completion is allowed only for interactive shell with init script for '%s'", title)
+	}
+	if (i.Completion.Marker == nil) == (i.Completion.Prompt == nil) {
+		v.errorf(p, "completion should specify either marker or prompt for '%s'", title)
+	}
+	if i.Completion.Marker != nil && !strings.Contains(*i.Completion.Marker, MarkerPlaceholder) {
+		v.errorf(p.with("marker"), "completion marker script should contain %s placeholder for '%s'", MarkerPlaceholder, title)
+	}
+	if i.Completion.Prompt != nil {
+		if i.Pty == nil || !*i.Pty {
+			v.errorf(p.with("prompt"), "completion prompt is supported only in PTY mode for '%s'", title)
+		}
+		if _, err := regexp.Compile(*i.Completion.Prompt); err != nil {
+			v.errorf(p.with("prompt"), "invalid completion prompt '%s' for '%s': %v", *i.Completion.Prompt, title, err)
+		}
+	}
+	if i.Completion.TimeoutMs != nil && *i.Completion.TimeoutMs <= 0 {
+		v.errorf(p.with("timeout-ms"), "completion timeout should be positive for '%s'", title)
+	}
+}
+
+func (v *validator) validateTransform(title string, p path, t TransformConfig) {
+
+	count := 0
+	for _, specified := range []bool{t.Regex != nil, t.JsonPath != nil, t.Unit != nil,
+		t.Multiply != nil, t.Add != nil, t.Trim != nil, t.Script != nil} {
+		if specified {
+			count++
+		}
+	}
+
+	if count != 1 {
+		v.errorf(p, "each transform step should specify exactly one of regex, json, unit, multiply, add, trim or script for '%s'", title)
+	}
+
+	if t.Regex != nil {
+		if _, err := regexp.Compile(*t.Regex); err != nil {
+			v.errorf(p.with("regex"), "invalid transform regex '%s' for '%s': %v", *t.Regex, title, err)
+		}
+	}
+
+	if t.Unit != nil {
+		for _, u := range TransformUnits {
+			if u == *t.Unit {
+				return
+			}
+		}
+		v.errorf(p.with("unit"), "unknown transform unit '%s' for '%s'", *t.Unit, title)
+	}
+}
+
+func (v *validator) validateSource(ref componentRef) {
+
+	source := ref.config.Source
+	if source == nil {
+		return
+	}
+
+	count := 0
+	for _, specified := range []bool{source.Fifo != nil, source.File != nil, source.Unix != nil, source.Tcp != nil} {
+		if specified {
+			count++
+		}
+	}
+
+	if count != 1 {
+		v.errorf(ref.path.with("source"), "source should specify exactly one of fifo, file, unix or tcp for '%s'", ref.config.Title)
+	}
+}
+
+func (v *validator) validateLayout(c *Config) {
+
+	if c.Layout != nil && *c.Layout != LayoutManual && *c.Layout != LayoutAuto {
+		v.errorf(path{"layout"}, "unknown layout '%s'. Use '%s' or '%s'", *c.Layout, LayoutManual, LayoutAuto)
+	}
+
+	if c.Tiling != nil && !containsTiling(*c.Tiling) {
+		v.errorf(path{"tiling"}, "unknown tiling '%s'. Use '%s', '%s' or '%s'", *c.Tiling, TilingGrid, TilingColumns, TilingTreemap)
+	}
+
+	if c.Grid != nil && (c.Grid.Columns < 0 || c.Grid.Rows < 0) {
+		v.errorf(path{"grid"}, "grid columns and rows should be positive")
+	}
+
+	for name, width := range c.Breakpoints {
+		if width <= 0 {
+			v.errorf(path{"breakpoints", name}, "max width of the '%s' breakpoint should be positive", name)
+		}
+	}
+}
+
+// validatePosition checks position format and whether the component fits the grid.
+// Components without position are arranged automatically
+func (v *validator) validatePosition(ref componentRef, grid GridConfig) {
+	if ref.config.Position != nil {
+		v.validateRectangle(ref.config.Title, ref.config.Position, ref.path.with("position"), grid)
+	}
+}
+
+// validateBreakpointPositions checks positions, which replace the default one on narrow terminals
+func (v *validator) validateBreakpointPositions(ref componentRef, breakpoints map[string]int, grid GridConfig) {
+
+	var names []string
+	for name := range ref.config.Positions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		p := ref.path.with("positions", name)
+		if _, ok := breakpoints[name]; !ok {
+			v.errorf(p, "unknown breakpoint '%s' for '%s'. Please define it in breakpoints", name, ref.config.Title)
+			continue
+		}
+		v.validateRectangle(ref.config.Title, ref.config.Positions[name], p, grid)
+	}
+}
+
+func containsTiling(tiling Tiling) bool {
+	for _, t := range Tilings {
+		if t == tiling {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *validator) validateWeight(ref componentRef) {
+	if ref.config.Weight != nil && *ref.config.Weight <= 0 {
+		v.errorf(ref.path.with("weight"), "weight should be positive for '%s'", ref.config.Title)
+	}
+}
+
+func (v *validator) validateRectangle(title string, position [][]int, p path, grid GridConfig) {
+
+	if len(position) != 2 || len(position[0]) != 2 || len(position[1]) != 2 {
+		v.errorf(p, "position should be in [[x, y], [width, height]] format for '%s'", title)
+		return
+	}
+
+	r := image.Rect(position[0][0], position[0][1], position[0][0]+position[1][0], position[0][1]+position[1][1])
+
+	if position[1][0] <= 0 || position[1][1] <= 0 {
+		v.errorf(p, "width and height should be positive for '%s'", title)
+	} else if !r.In(image.Rect(0, 0, grid.Columns, grid.Rows)) {
+		v.warnf(p, "'%s' is outside of the %dx%d grid", title, grid.Columns, grid.Rows)
+	}
+}
+
+func (v *validator) validateOverlaps(components []componentRef) {
+	for i, a := range components {
+		for _, b := range components[:i] {
+			if hasValidPosition(a.config) && hasValidPosition(b.config) &&
+				a.config.GetRectangle().Overlaps(b.config.GetRectangle()) {
+				v.warnf(a.path.with("position"), "'%s' overlaps with '%s'", a.config.Title, b.config.Title)
+			}
+		}
+	}
+}
+
+func hasValidPosition(c ComponentConfig) bool {
+	p := c.Position
+	return len(p) == 2 && len(p[0]) == 2 && len(p[1]) == 2 && p[1][0] > 0 && p[1][1] > 0
+}
+
+func (v *validator) validateColor(color *ui.Color, p path) {
+	if color != nil && (*color < minColor || *color > maxColor) {
+		v.errorf(p, "invalid color %d. Use 0-255 from the xterm-256 palette", *color)
+	}
+}
+
+func (v *validator) validateThemes(c *Config) {
+
+	if c.Theme != nil && !isBuiltInTheme(*c.Theme) {
+		if _, ok := c.Themes[string(*c.Theme)]; !ok {
+			v.errorf(path{"theme"}, "unknown theme '%s'. Use one of the built-in themes %v, or define it in themes", *c.Theme, console.Themes)
+		}
+	}
+
+	for name, theme := range c.Themes {
+
+		p := path{"themes", name}
+
+		if theme.Extends != nil && !isBuiltInTheme(*theme.Extends) {
+			v.errorf(p.with("extends"), "theme '%s' should extend one of the built-in themes %v", name, console.Themes)
+		}
+		for i, color := range theme.ContentColors {
+			v.validateThemeColor(name, color, p.with("content-colors", i))
+		}
+		for i, gradient := range theme.GradientColors {
+			if len(gradient) == 0 {
+				v.errorf(p.with("gradient-colors", i), "gradient of theme '%s' should contain colors", name)
+			}
+			for j, color := range gradient {
+				v.validateThemeColor(name, color, p.with("gradient-colors", i, j))
+			}
+		}
+		for key, color := range map[string]*ThemeColor{
+			"base-color":         theme.BaseColor,
+			"medium-color":       theme.MediumColor,
+			"reverse-color":      theme.ReverseColor,
+			"menu-color":         theme.MenuColor,
+			"menu-color-reverse": theme.MenuColorReverse,
+		} {
+			if color != nil {
+				v.validateThemeColor(name, *color, p.with(key))
+			}
+		}
+	}
+}
+
+func (v *validator) validateKeyBindings(c *Config) {
+
+	for action, keys := range c.Keys {
+
+		p := path{"keys", string(action)}
+
+		if !isAction(action) {
+			v.errorf(p, "unknown action '%s'. Supported actions: %v", action, console.Actions)
+			continue
+		}
+		if len(keys) == 0 {
+			v.errorf(p, "keys should be specified for '%s'", action)
+		}
+		for i, key := range keys {
+			if !console.IsKey(key) {
+				v.errorf(p.with(i), "unknown key '%s' for '%s'. Use a single character or a key name, e.g. <Enter>, <C-c> or <F1>", key, action)
+			}
+			if key == console.KeyQuit3 && action != console.ActionQuit {
+				v.errorf(p.with(i), "key '%s' is reserved for 'quit', so sampler can always be stopped", key)
+			}
+		}
+	}
+
+	// defaults don't conflict with each other, so at least one of the actions is set in config
+	for _, conflict := range c.GetKeys().GetConflicts() {
+		if conflict.Key == console.KeyQuit3 {
+			continue
+		}
+		action := conflict.Second
+		if _, ok := c.Keys[action]; !ok {
+			action = conflict.First
+		}
+		v.errorf(path{"keys", string(action)}, "key '%s' is bound to both '%s' and '%s'",
+			conflict.Key, conflict.First, conflict.Second)
+	}
+}
+
+func isAction(action console.Action) bool {
+	for _, a := range console.Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *validator) validateThemeColor(theme string, color ThemeColor, p path) {
+	if _, err := console.ParseColor(string(color)); err != nil {
+		v.errorf(p, "theme '%s' has %v", theme, err)
+	}
+}
+
+func isBuiltInTheme(theme console.Theme) bool {
+	for _, t := range console.Themes {
+		if t == theme {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *validator) validateStatsd(c *Config, items []itemRef) {
+
+	if c.Statsd != nil {
+		if len(c.Statsd.Addr) == 0 {
+			v.errorf(path{"statsd"}, "statsd listener address should be specified")
+		}
+		if c.Statsd.FlushMs != nil && *c.Statsd.FlushMs <= 0 {
+			v.errorf(path{"statsd", "flush-ms"}, "statsd flush interval should be positive")
+		}
+		return
+	}
+
 	for _, i := range items {
+		if i.item.Statsd != nil {
+			v.errorf(i.path.with("statsd"), "statsd listener should be configured to use '%s' metric", *i.item.Statsd)
+		}
+	}
+}
+
+func (v *validator) validateLabels(title string, p path, items []Item) {
+	labels := make(map[string]bool)
+	for j, i := range items {
+		if i.Label == nil {
+			v.errorf(p.with("items", j), "item label should be specified for '%s'", title)
+			continue
+		}
 		label := *i.Label
 		if _, contains := labels[label]; contains {
-			console.Exit(fmt.Sprintf("Config validation error: item labels should be unique. Please rename '%s' for '%s'", label, title))
+			v.errorf(p.with("items", j, "label"), "item labels should be unique. Please rename '%s' for '%s'", label, title)
 		}
 		labels[label] = true
 	}
 }
 
-func validateTitlesUniqueness(components []ComponentConfig) {
+func (v *validator) validateLines(title string, p path, items []Item) {
+	for j, i := range items {
+		if i.Line != nil && !containsLineStyle(*i.Line) {
+			v.errorf(p.with("items", j, "line"), "unknown line '%s' for '%s'. Supported lines: %v", *i.Line, title, LineStyles)
+		}
+		if i.Marker != nil && utf8.RuneCountInString(*i.Marker) != 1 {
+			v.errorf(p.with("items", j, "marker"), "marker should be a single character for '%s'", title)
+		}
+	}
+}
+
+func containsLineStyle(style LineStyle) bool {
+	for _, s := range LineStyles {
+		if s == style {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *validator) validateTitlesUniqueness(components []componentRef) {
 	titles := make(map[string]bool)
 	for _, c := range components {
-		if _, contains := titles[c.Title]; contains {
-			console.Exit(fmt.Sprintf("Config validation error: component titles should be unique. Please rename '%s'", c.Title))
+		if _, contains := titles[c.config.Title]; contains {
+			v.errorf(c.path.with("title"), "component titles should be unique. Please rename '%s'", c.config.Title)
+		}
+		titles[c.config.Title] = true
+	}
+}
+
+func getRefs(c *Config) ([]componentRef, []itemRef) {
+
+	var components []componentRef
+	var items []itemRef
+
+	for i, c := range c.RunCharts {
+		p := path{"runcharts", i}
+		components = append(components, componentRef{c.ComponentConfig, p})
+		for j, item := range c.Items {
+			items = append(items, itemRef{item, c.ComponentConfig, p.with("items", j), false})
+		}
+	}
+	for i, c := range c.BarCharts {
+		p := path{"barcharts", i}
+		components = append(components, componentRef{c.ComponentConfig, p})
+		for j, item := range c.Items {
+			items = append(items, itemRef{item, c.ComponentConfig, p.with("items", j), true})
 		}
-		titles[c.Title] = true
 	}
+	for i, c := range c.SparkLines {
+		p := path{"sparklines", i}
+		components = append(components, componentRef{c.ComponentConfig, p})
+		items = append(items, itemRef{c.Item, c.ComponentConfig, p, false})
+	}
+	for i, c := range c.Gauges {
+		p := path{"gauges", i}
+		components = append(components, componentRef{c.ComponentConfig, p})
+		items = append(items,
+			itemRef{c.Min, c.ComponentConfig, p.with("min"), false},
+			itemRef{c.Max, c.ComponentConfig, p.with("max"), false},
+			itemRef{c.Cur, c.ComponentConfig, p.with("cur"), false})
+	}
+	for i, c := range c.AsciiBoxes {
+		p := path{"asciiboxes", i}
+		components = append(components, componentRef{c.ComponentConfig, p})
+		items = append(items, itemRef{c.Item, c.ComponentConfig, p, false})
+	}
+	for i, c := range c.TextBoxes {
+		p := path{"textboxes", i}
+		components = append(components, componentRef{c.ComponentConfig, p})
+		items = append(items, itemRef{c.Item, c.ComponentConfig, p, false})
+	}
+
+	return components, items
 }
@@ -0,0 +1,319 @@
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const invalidSource = `theme: dark
+runcharts:
+  - title: Latency
+    position: [[0, 0], [50, 30]]
+    colour: 5
+    items:
+      - sample: echo 1
+      - label: api
+        color: 300
+        sample: echo 1
+  - title: Latency
+    position: [[40, 20], [50, 30]]
+    items:
+      - label: api
+        sample: echo 2
+asciiboxes:
+  - title: Clock
+    position: [[0, 30], [10, 5]]
+    font: 4d
+    sample: date
+`
+
+func TestValidate(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "config.yml")
+	if err := ioutil.WriteFile(file, []byte(invalidSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Problem{
+		{5, 5, SeverityError, "unknown key 'colour'"},
+		{7, 9, SeverityError, "item label should be specified for 'Latency'"},
+		{9, 16, SeverityError, "invalid color 300. Use 0-255 from the xterm-256 palette"},
+		{11, 12, SeverityError, "component titles should be unique. Please rename 'Latency'"},
+		{12, 15, SeverityWarning, "'Latency' is outside of the 80x40 grid"},
+		{12, 15, SeverityWarning, "'Latency' overlaps with 'Latency'"},
+		{19, 11, SeverityError, "unknown font '4d' for 'Clock'. Use '2d' or '3d'"},
+	}
+
+	got := Validate(file)
+
+	if len(got) != len(want) {
+		t.Fatalf("Validate() returned %d problems, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Validate() problem %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+const breakpointsInvalidSource = `grid:
+  columns: 120
+  rows: 60
+breakpoints:
+  narrow: 0
+textboxes:
+  - title: Status
+    position: [[80, 0], [40, 60]]
+    positions:
+      narrow: [[0, 0], [130, 10]]
+      wide: [[0, 0], [120, 60]]
+    sample: uptime
+`
+
+func TestValidate_breakpoints(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "config.yml")
+	if err := ioutil.WriteFile(file, []byte(breakpointsInvalidSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Problem{
+		{5, 11, SeverityError, "max width of the 'narrow' breakpoint should be positive"},
+		{10, 15, SeverityWarning, "'Status' is outside of the 120x60 grid"},
+		{11, 13, SeverityError, "unknown breakpoint 'wide' for 'Status'. Please define it in breakpoints"},
+	}
+
+	got := Validate(file)
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() = %v, want %v", got, want)
+	}
+}
+
+const autoLayoutInvalidSource = `layout: auto
+tiling: spiral
+textboxes:
+  - title: Status
+    weight: 0
+    sample: uptime
+  - title: Uptime
+    position: [[0, 0], [80, 40]]
+    sample: uptime
+  - title: Load
+    position: [[0, 0], [80, 40]]
+    sample: uptime
+`
+
+func TestValidate_autoLayout(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "config.yml")
+	if err := ioutil.WriteFile(file, []byte(autoLayoutInvalidSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// overlapping positions are ignored, since components are tiled
+	want := []Problem{
+		{2, 9, SeverityError, "unknown tiling 'spiral'. Use 'grid', 'columns' or 'treemap'"},
+		{5, 13, SeverityError, "weight should be positive for 'Status'"},
+	}
+
+	got := Validate(file)
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() = %v, want %v", got, want)
+	}
+}
+
+const themesInvalidSource = `theme: solarized
+themes:
+  contrast:
+    extends: solarized
+    base-color: '#12345'
+    gradient-colors:
+      - []
+      - [16, 256]
+textboxes:
+  - title: Status
+    sample: uptime
+`
+
+func TestValidate_themes(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "config.yml")
+	if err := ioutil.WriteFile(file, []byte(themesInvalidSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Problem{
+		{1, 8, SeverityError, "unknown theme 'solarized'. Use one of the built-in themes [dark light dark-colorblind light-colorblind], or define it in themes"},
+		{4, 14, SeverityError, "theme 'contrast' should extend one of the built-in themes [dark light dark-colorblind light-colorblind]"},
+		{5, 17, SeverityError, "theme 'contrast' has invalid color '#12345'. Use xterm-256 number, #rrggbb or rgb(r, g, b)"},
+		{7, 9, SeverityError, "gradient of theme 'contrast' should contain colors"},
+		{8, 14, SeverityError, "theme 'contrast' has invalid color 256. Use 0-255 from the xterm-256 palette"},
+	}
+
+	got := Validate(file)
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() = %v, want %v", got, want)
+	}
+}
+
+const linesInvalidSource = `theme: dark-colorblind
+runcharts:
+  - title: Latency
+    items:
+      - label: api
+        line: wavy
+        sample: echo 1
+      - label: db
+        marker: '**'
+        sample: echo 2
+`
+
+func TestValidate_lines(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "config.yml")
+	if err := ioutil.WriteFile(file, []byte(linesInvalidSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Problem{
+		{6, 15, SeverityError, "unknown line 'wavy' for 'Latency'. Supported lines: [solid dashed dotted]"},
+		{9, 17, SeverityError, "marker should be a single character for 'Latency'"},
+	}
+
+	got := Validate(file)
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() = %v, want %v", got, want)
+	}
+}
+
+const prometheusInvalidSource = `sparklines:
+  - title: Requests
+    prometheus:
+      url: http://localhost:9090/metrics
+      query: rate(http_requests_total{code="200"}
+`
+
+func TestValidate_prometheus(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "config.yml")
+	if err := ioutil.WriteFile(file, []byte(prometheusInvalidSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Problem{
+		{5, 14, SeverityError, "invalid prometheus query 'rate(http_requests_total{code=\"200\"}' for 'Requests': closing parenthesis is missing"},
+	}
+
+	got := Validate(file)
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() = %v, want %v", got, want)
+	}
+}
+
+const keysInvalidSource = `keys:
+  quit: [x]
+  pause: [a, <C-c>]
+  jump: [j]
+  left: [h, <Hyper>]
+  up: []
+textboxes:
+  - title: Clock
+    sample: date
+`
+
+func TestValidate_keys(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "config.yml")
+	if err := ioutil.WriteFile(file, []byte(keysInvalidSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Problem{
+		{3, 10, SeverityError, "key 'a' is bound to both 'pause' and 'add'"},
+		{3, 14, SeverityError, "key '<C-c>' is reserved for 'quit', so sampler can always be stopped"},
+		{4, 9, SeverityError, "unknown action 'jump'. Supported actions: [quit pause add select reset left right up down help stats]"},
+		{5, 13, SeverityError, "unknown key '<Hyper>' for 'left'. Use a single character or a key name, e.g. <Enter>, <C-c> or <F1>"},
+		{6, 7, SeverityError, "keys should be specified for 'up'"},
+	}
+
+	got := Validate(file)
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() = %v, want %v", got, want)
+	}
+}
+
+const statsdInvalidSource = `statsd:
+  addr: :8125
+  flush-ms: 0
+sparklines:
+  - title: Requests
+    statsd: requests
+`
+
+func TestValidate_statsd(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "config.yml")
+	if err := ioutil.WriteFile(file, []byte(statsdInvalidSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Problem{
+		{3, 13, SeverityError, "statsd flush interval should be positive"},
+	}
+
+	got := Validate(file)
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() = %v, want %v", got, want)
+	}
+}
+
+func TestValidate_syntaxError(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "config.yml")
+	if err := ioutil.WriteFile(file, []byte("runcharts:\n  - title: [a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := Validate(file)
+
+	if len(got) != 1 || got[0].Severity != SeverityError || got[0].Line == 0 {
+		t.Errorf("Validate() = %v, want a single located error", got)
+	}
+}
+
+func TestProblem_Format(t *testing.T) {
+	p := Problem{Line: 3, Column: 7, Severity: SeverityError, Message: "invalid color 300"}
+	if got := p.Format("config.yml"); got != "config.yml:3:7: error: invalid color 300" {
+		t.Errorf("Format() = %q", got)
+	}
+}
+
+func TestSuggestKey(t *testing.T) {
+	fields := getYamlFields(reflect.TypeOf(RunChartConfig{}))
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"rate_ms", "rate-ms"},
+		{"Title", "title"},
+		{"itmes", "items"},
+		{"scael", "scale"},
+		{"something", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := suggestKey(tt.input, fields); got != tt.want {
+				t.Errorf("suggestKey(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package console
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	ui "github.com/gizak/termui/v3"
+)
+
+var (
+	hexColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+	rgbColorPattern = regexp.MustCompile(`^(?i)rgb\(\s*(\d{1,3})\s*,\s*(\d{1,3})\s*,\s*(\d{1,3})\s*\)$`)
+)
+
+// cubeLevels are the channel intensities of the 6x6x6 color cube, which takes 16-231 of the xterm-256 palette
+var cubeLevels = []int{0, 95, 135, 175, 215, 255}
+
+// basicColors are the default xterm values of the first 16 colors
+var basicColors = [][3]int{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0}, {0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0}, {92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+const (
+	cubeStart      = 16
+	greyscaleStart = 232
+	greyscaleSize  = 24
+)
+
+// rgbColorFlag marks colors, which keep RGB value in the lower bits instead of xterm-256 number
+const rgbColorFlag ui.Color = 1 << 24
+
+// NewRGBColor returns a color, which is rendered as is in truecolor terminals,
+// and mapped to the nearest available color in the others
+func NewRGBColor(r, g, b int) ui.Color {
+	return rgbColorFlag | ui.Color(r<<16|g<<8|b)
+}
+
+func isRGB(color ui.Color) bool {
+	return color >= 0 && color&rgbColorFlag != 0
+}
+
+// getRGB returns the RGB value of the color, as it's displayed with the default xterm palette
+func getRGB(color ui.Color) (int, int, int) {
+	switch {
+	case isRGB(color):
+		return int(color >> 16 & 0xff), int(color >> 8 & 0xff), int(color & 0xff)
+	case color < cubeStart:
+		c := basicColors[color]
+		return c[0], c[1], c[2]
+	case color < greyscaleStart:
+		i := int(color - cubeStart)
+		return cubeLevels[i/36], cubeLevels[i/6%6], cubeLevels[i%6]
+	default:
+		level := 8 + int(color-greyscaleStart)*10
+		return level, level, level
+	}
+}
+
+// getNearestBasicColor maps the color to the first 8 or 16 colors
+func getNearestBasicColor(color ui.Color, count int) ui.Color {
+
+	if !isRGB(color) && int(color) < count {
+		return color
+	}
+
+	r, g, b := getRGB(color)
+	nearest, distance := ui.Color(0), -1
+
+	for i, c := range basicColors[:count] {
+		if d := getDistance(r, g, b, c[0], c[1], c[2]); distance < 0 || d < distance {
+			nearest, distance = ui.Color(i), d
+		}
+	}
+
+	return nearest
+}
+
+// ParseColor accepts xterm-256 color number, #rgb, #rrggbb or rgb(r, g, b) value.
+// Hex and RGB values are rendered as is in truecolor terminals only, see NewRGBColor
+func ParseColor(value string) (ui.Color, error) {
+
+	value = strings.TrimSpace(value)
+
+	if number, err := strconv.Atoi(value); err == nil {
+		if number < int(ColorClear) || number > 255 {
+			return 0, fmt.Errorf("invalid color %d. Use 0-255 from the xterm-256 palette", number)
+		}
+		return ui.Color(number), nil
+	}
+
+	if match := hexColorPattern.FindStringSubmatch(value); match != nil {
+		hex := match[1]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		rgb, _ := strconv.ParseUint(hex, 16, 32)
+		return NewRGBColor(int(rgb>>16), int(rgb>>8&0xff), int(rgb&0xff)), nil
+	}
+
+	if match := rgbColorPattern.FindStringSubmatch(value); match != nil {
+		var rgb [3]int
+		for i := range rgb {
+			rgb[i], _ = strconv.Atoi(match[i+1])
+			if rgb[i] > 255 {
+				return 0, fmt.Errorf("invalid color '%s'. RGB values should be 0-255", value)
+			}
+		}
+		return NewRGBColor(rgb[0], rgb[1], rgb[2]), nil
+	}
+
+	return 0, fmt.Errorf("invalid color '%s'. Use xterm-256 number, #rrggbb or rgb(r, g, b)", value)
+}
+
+// GetNearestColor returns the closest xterm-256 color from the color cube or the greyscale ramp.
+// First 16 colors are skipped, since they are redefined by terminal themes
+func GetNearestColor(r, g, b int) ui.Color {
+
+	nearest, distance := ui.Color(0), -1
+
+	for i := 0; i < len(cubeLevels)*len(cubeLevels)*len(cubeLevels); i++ {
+		cr, cg, cb := cubeLevels[i/36], cubeLevels[i/6%6], cubeLevels[i%6]
+		if d := getDistance(r, g, b, cr, cg, cb); distance < 0 || d < distance {
+			nearest, distance = ui.Color(cubeStart+i), d
+		}
+	}
+
+	for i := 0; i < greyscaleSize; i++ {
+		level := 8 + i*10
+		if d := getDistance(r, g, b, level, level, level); d < distance {
+			nearest, distance = ui.Color(greyscaleStart+i), d
+		}
+	}
+
+	return nearest
+}
+
+func getDistance(r1, g1, b1, r2, g2, b2 int) int {
+	return (r1-r2)*(r1-r2) + (g1-g2)*(g1-g2) + (b1-b2)*(b1-b2)
+}
@@ -0,0 +1,56 @@
+package console
+
+import (
+	"testing"
+
+	ui "github.com/gizak/termui/v3"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  ui.Color
+		err   bool
+	}{
+		{"should keep xterm-256 number", "178", 178, false},
+		{"should keep clear color", "-1", ColorClear, false},
+		{"should keep hex value", "#5fafff", NewRGBColor(0x5f, 0xaf, 0xff), false},
+		{"should expand short hex value", "#F00", NewRGBColor(0xff, 0, 0), false},
+		{"should keep rgb value", "rgb(0, 135, 0)", NewRGBColor(0, 135, 0), false},
+		{"should keep uppercase rgb without spaces", "RGB(255,255,255)", NewRGBColor(255, 255, 255), false},
+		{"should reject number out of palette", "256", 0, true},
+		{"should reject rgb out of range", "rgb(300, 0, 0)", 0, true},
+		{"should reject invalid hex", "#12345", 0, true},
+		{"should reject color name", "red", 0, true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseColor(test.input)
+		if (err != nil) != test.err {
+			t.Errorf("%s: ParseColor(%q) error = %v", test.name, test.input, err)
+		} else if got != test.want {
+			t.Errorf("%s: ParseColor(%q) = %d, want %d", test.name, test.input, got, test.want)
+		}
+	}
+}
+
+func TestGetNearestColor(t *testing.T) {
+	tests := []struct {
+		name string
+		rgb  [3]int
+		want ui.Color
+	}{
+		{"should map to the color cube", [3]int{0x5f, 0xaf, 0xff}, 75},
+		{"should map red to the color cube", [3]int{0xff, 0, 0}, 196},
+		{"should map grey to the greyscale ramp", [3]int{0x30, 0x30, 0x30}, 236},
+		{"should map solarized base03 to the nearest color", [3]int{0x00, 0x2b, 0x36}, 234},
+		{"should map white to the color cube", [3]int{255, 255, 255}, 231},
+	}
+
+	for _, test := range tests {
+		if got := GetNearestColor(test.rgb[0], test.rgb[1], test.rgb[2]); got != test.want {
+			t.Errorf("%s: GetNearestColor(%v) = %d, want %d", test.name, test.rgb, got, test.want)
+		}
+	}
+}
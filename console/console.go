@@ -7,6 +7,7 @@ import (
 	"time"
 
 	ui "github.com/gizak/termui/v3"
+	tb "github.com/nsf/termbox-go"
 )
 
 const (
@@ -29,18 +30,23 @@ const (
 	AsciiFont3D AsciiFont = "3d"
 )
 
-func Init() {
+// Init initializes termui with the color mode, which components are rendered in
+func Init(mode ColorMode) {
 
 	fmt.Printf("\033]0;%s\007", AppTitle)
 
 	if err := ui.Init(); err != nil {
 		log.Fatalf("Failed to initialize ui: %v", err)
 	}
+
+	colorMode = mode
+	tb.SetOutputMode(outputModes[mode])
 }
 
 // Close function calls Close from termui package,
 // which closes termbox-go
 func Close() {
+	closeMouseMotion()
 	ui.Close()
 }
 
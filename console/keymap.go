@@ -0,0 +1,136 @@
+package console
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Action is a command, which is triggered by the bound keys
+type Action string
+
+const (
+	ActionQuit   Action = "quit"
+	ActionPause  Action = "pause"
+	ActionAdd    Action = "add"
+	ActionSelect Action = "select"
+	ActionReset  Action = "reset"
+	ActionLeft   Action = "left"
+	ActionRight  Action = "right"
+	ActionUp     Action = "up"
+	ActionDown   Action = "down"
+	ActionHelp   Action = "help"
+	ActionStats  Action = "stats"
+)
+
+var Actions = []Action{ActionQuit, ActionPause, ActionAdd, ActionSelect, ActionReset, ActionLeft, ActionRight, ActionUp, ActionDown, ActionHelp, ActionStats}
+
+// KeyMap lists the keys, which are bound to the actions
+type KeyMap map[Action][]string
+
+// namedKeyPattern matches termui names of the special keys, e.g. <Enter>, <C-c> or <F1>
+var namedKeyPattern = regexp.MustCompile(`^<(F([1-9]|1[0-2])|Insert|Delete|Home|End|PageUp|PageDown|Up|Down|Left|Right|Tab|Enter|Escape|Space|Backspace|C-[a-z4-7]|C-<Space>|C-<Backspace>|M-.)>$`)
+
+var keyNames = map[string]string{
+	KeyEsc:   "ESC",
+	KeyEnter: "ENTER",
+	KeySpace: "SPACE",
+	KeyLeft:  "←",
+	KeyRight: "→",
+	KeyUp:    "↑",
+	KeyDown:  "↓",
+}
+
+// GetDefaultKeyMap returns the bindings, which are used for the actions missing in config
+func GetDefaultKeyMap() KeyMap {
+	return KeyMap{
+		ActionQuit:   {KeyQuit1, KeyQuit2, KeyQuit3},
+		ActionPause:  {KeyPause1, KeyPause2},
+		ActionAdd:    {KeyAdd1, KeyAdd2},
+		ActionSelect: {KeyEnter},
+		ActionReset:  {KeyEsc},
+		ActionLeft:   {KeyLeft},
+		ActionRight:  {KeyRight},
+		ActionUp:     {KeyUp},
+		ActionDown:   {KeyDown},
+		ActionHelp:   {KeyHelp},
+		ActionStats:  {KeyStats},
+	}
+}
+
+// GetAction returns the action, which the key is bound to, or an empty one
+func (m KeyMap) GetAction(key string) Action {
+	for action, keys := range m {
+		for _, k := range keys {
+			if k == key {
+				return action
+			}
+		}
+	}
+	return ""
+}
+
+// GetHint returns the first key of the action, formatted to be shown in the hints
+func (m KeyMap) GetHint(action Action) string {
+	if len(m[action]) == 0 {
+		return "?"
+	}
+	return FormatKey(m[action][0])
+}
+
+// GetKeys returns all the keys of the action, formatted to be shown in the help
+func (m KeyMap) GetKeys(action Action) string {
+	var keys []string
+	for _, key := range m[action] {
+		keys = append(keys, FormatKey(key))
+	}
+	return strings.Join(keys, "/")
+}
+
+// GetSelectionHint returns the keys, which move the selection, e.g. <-> or h/l
+func (m KeyMap) GetSelectionHint() string {
+	left, right := m.GetHint(ActionLeft), m.GetHint(ActionRight)
+	if left == keyNames[KeyLeft] && right == keyNames[KeyRight] {
+		return "<->"
+	}
+	return left + "/" + right
+}
+
+// FormatKey returns a short key name, e.g. ESC for <Escape>, or C-c for <C-c>
+func FormatKey(key string) string {
+	if name, ok := keyNames[key]; ok {
+		return name
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(key, "<"), ">")
+}
+
+// IsKey returns true for a single character, or a name of the special key
+func IsKey(key string) bool {
+	return utf8.RuneCountInString(key) == 1 || namedKeyPattern.MatchString(key)
+}
+
+// KeyConflict is a key, which is bound to two actions
+type KeyConflict struct {
+	Key    string
+	First  Action
+	Second Action
+}
+
+// GetConflicts returns the keys, which are bound to several actions
+func (m KeyMap) GetConflicts() []KeyConflict {
+
+	var conflicts []KeyConflict
+	bound := make(map[string]Action)
+
+	for _, action := range Actions {
+		for _, key := range m[action] {
+			if other, ok := bound[key]; ok && other != action {
+				conflicts = append(conflicts, KeyConflict{Key: key, First: other, Second: action})
+				continue
+			}
+			bound[key] = action
+		}
+	}
+
+	return conflicts
+}
@@ -0,0 +1,95 @@
+package console
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKeyMap_GetAction(t *testing.T) {
+
+	keys := GetDefaultKeyMap()
+	keys[ActionLeft] = []string{"h", KeyLeft}
+
+	tests := []struct {
+		key  string
+		want Action
+	}{
+		{"q", ActionQuit},
+		{KeyQuit3, ActionQuit},
+		{"h", ActionLeft},
+		{KeyLeft, ActionLeft},
+		{KeyEnter, ActionSelect},
+		{"x", ""},
+	}
+
+	for _, test := range tests {
+		if got := keys.GetAction(test.key); got != test.want {
+			t.Errorf("GetAction(%q) = %q, want %q", test.key, got, test.want)
+		}
+	}
+}
+
+func TestKeyMap_GetHint(t *testing.T) {
+
+	keys := GetDefaultKeyMap()
+	if got := keys.GetSelectionHint(); got != "<->" {
+		t.Errorf("GetSelectionHint() = %q, want %q", got, "<->")
+	}
+	if got := keys.GetHint(ActionReset); got != "ESC" {
+		t.Errorf("GetHint(reset) = %q, want %q", got, "ESC")
+	}
+
+	keys[ActionLeft] = []string{"h"}
+	keys[ActionRight] = []string{"l", KeyRight}
+	keys[ActionQuit] = []string{"<C-x>"}
+
+	if got := keys.GetSelectionHint(); got != "h/l" {
+		t.Errorf("GetSelectionHint() = %q, want %q", got, "h/l")
+	}
+	if got := keys.GetHint(ActionQuit); got != "C-x" {
+		t.Errorf("GetHint(quit) = %q, want %q", got, "C-x")
+	}
+	if got := keys.GetKeys(ActionRight); got != "l/→" {
+		t.Errorf("GetKeys(right) = %q, want %q", got, "l/→")
+	}
+}
+
+func TestIsKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"x", true},
+		{"ж", true},
+		{"<Enter>", true},
+		{"<C-c>", true},
+		{"<F12>", true},
+		{"<C-<Space>>", true},
+		{"<M-x>", true},
+		{"", false},
+		{"xy", false},
+		{"<F13>", false},
+		{"<Hyper>", false},
+	}
+
+	for _, test := range tests {
+		if got := IsKey(test.key); got != test.want {
+			t.Errorf("IsKey(%q) = %v, want %v", test.key, got, test.want)
+		}
+	}
+}
+
+func TestKeyMap_GetConflicts(t *testing.T) {
+
+	keys := GetDefaultKeyMap()
+	if conflicts := keys.GetConflicts(); len(conflicts) > 0 {
+		t.Errorf("GetConflicts() of the default keys = %v, want none", conflicts)
+	}
+
+	keys[ActionDown] = []string{"j", "p"}
+	want := []KeyConflict{{Key: "p", First: ActionPause, Second: ActionDown}}
+
+	if got := keys.GetConflicts(); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetConflicts() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,43 @@
+//go:build !windows
+// +build !windows
+
+package console
+
+import "os"
+
+// termbox reports mouse motion only while a button is pressed,
+// any-event tracking is required to report the hover as well
+const (
+	mouseMotionEnable  = "\033[?1003h"
+	mouseMotionDisable = "\033[?1003l"
+)
+
+// tty is the terminal, which termbox writes to, so the sequences don't go to the redirected stdout
+var tty *os.File
+
+// SetMouseMotion switches the hover reporting. It's enabled only while the hover is handled,
+// so the terminal isn't flooded with motion events in the other modes. Call it between renders,
+// since termbox doesn't know about the sequence
+func SetMouseMotion(enabled bool) {
+
+	if tty == nil {
+		var err error
+		if tty, err = os.OpenFile("/dev/tty", os.O_WRONLY, 0); err != nil {
+			return
+		}
+	}
+
+	if enabled {
+		_, _ = tty.WriteString(mouseMotionEnable)
+	} else {
+		_, _ = tty.WriteString(mouseMotionDisable)
+	}
+}
+
+func closeMouseMotion() {
+	if tty != nil {
+		SetMouseMotion(false)
+		_ = tty.Close()
+		tty = nil
+	}
+}
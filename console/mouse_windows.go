@@ -0,0 +1,7 @@
+package console
+
+// SetMouseMotion does nothing on Windows, where termbox reads the console input events,
+// rather than the escape sequences
+func SetMouseMotion(enabled bool) {}
+
+func closeMouseMotion() {}
@@ -1,7 +1,6 @@
 package console
 
 import (
-	"fmt"
 	"runtime"
 
 	ui "github.com/gizak/termui/v3"
@@ -10,10 +9,14 @@ import (
 type Theme string
 
 const (
-	ThemeDark  Theme = "dark"
-	ThemeLight Theme = "light"
+	ThemeDark            Theme = "dark"
+	ThemeLight           Theme = "light"
+	ThemeDarkColorblind  Theme = "dark-colorblind"
+	ThemeLightColorblind Theme = "light-colorblind"
 )
 
+var Themes = []Theme{ThemeDark, ThemeLight, ThemeDarkColorblind, ThemeLightColorblind}
+
 const (
 	ColorOlive       ui.Color = 178
 	ColorDeepSkyBlue ui.Color = 39
@@ -40,34 +43,50 @@ const (
 )
 
 type Palette struct {
-	ContentColors  []ui.Color
-	GradientColors [][]ui.Color
-	BaseColor      ui.Color
-	MediumColor    ui.Color
-	ReverseColor   ui.Color
+	ContentColors    []ui.Color
+	GradientColors   [][]ui.Color
+	BaseColor        ui.Color
+	MediumColor      ui.Color
+	ReverseColor     ui.Color
+	MenuColor        ui.Color
+	MenuColorReverse ui.Color
 }
 
-// GetPalette returns a color palette based on specified theme
+// GetPalette returns a color palette based on specified built-in theme.
+// Unknown themes are reported by config validation, so the dark palette is returned for them
 func GetPalette(theme Theme) Palette {
 	switch theme {
-	case ThemeDark:
-		return Palette{
-			ContentColors:  []ui.Color{ColorOlive, ColorDeepSkyBlue, ColorDeepPink, ColorWhite, ColorGrey, ColorGreen, ColorOrange, ColorCian, ColorPurple},
-			GradientColors: [][]ui.Color{{39, 33, 62, 93, 164, 161}, {95, 138, 180, 179, 178, 178}},
-			BaseColor:      ColorWhite,
-			MediumColor:    ColorDarkGrey,
-			ReverseColor:   ColorBlack,
-		}
+	case ThemeDarkColorblind:
+		// Okabe-Ito colors and cividis gradient are distinguishable with all types of color blindness
+		palette := GetPalette(ThemeDark)
+		palette.ContentColors = []ui.Color{178, 74, 35, 221, 166, 175, 25, ColorWhite}
+		palette.GradientColors = [][]ui.Color{{23, 239, 59, 243, 144, 179, 185, 221}}
+		return palette
+	case ThemeLightColorblind:
+		palette := GetPalette(ThemeLight)
+		palette.ContentColors = []ui.Color{ColorBlack, 25, 166, 35, 175, 74, 178}
+		palette.GradientColors = [][]ui.Color{{185, 179, 144, 243, 59, 239, 23, 17}}
+		return palette
 	case ThemeLight:
 		return Palette{
-			ContentColors:  []ui.Color{ColorBlack, ColorDarkRed, ColorBlueViolet, ColorGrey, ColorGreen},
-			GradientColors: [][]ui.Color{{250, 248, 246, 244, 242, 240, 238, 236, 234, 232, 16}},
-			BaseColor:      ColorBlack,
-			MediumColor:    ColorLightGrey,
-			ReverseColor:   ColorWhite,
+			ContentColors:    []ui.Color{ColorBlack, ColorDarkRed, ColorBlueViolet, ColorGrey, ColorGreen},
+			GradientColors:   [][]ui.Color{{250, 248, 246, 244, 242, 240, 238, 236, 234, 232, 16}},
+			BaseColor:        ColorBlack,
+			MediumColor:      ColorLightGrey,
+			ReverseColor:     ColorWhite,
+			MenuColor:        GetMenuColor(),
+			MenuColorReverse: GetMenuColorReverse(),
 		}
 	default:
-		panic(fmt.Sprintf("Specified theme is not supported: %v", theme))
+		return Palette{
+			ContentColors:    []ui.Color{ColorOlive, ColorDeepSkyBlue, ColorDeepPink, ColorWhite, ColorGrey, ColorGreen, ColorOrange, ColorCian, ColorPurple},
+			GradientColors:   [][]ui.Color{{39, 33, 62, 93, 164, 161}, {95, 138, 180, 179, 178, 178}},
+			BaseColor:        ColorWhite,
+			MediumColor:      ColorDarkGrey,
+			ReverseColor:     ColorBlack,
+			MenuColor:        GetMenuColor(),
+			MenuColorReverse: GetMenuColorReverse(),
+		}
 	}
 }
 
@@ -25,6 +25,8 @@ func TestGetPalette(t *testing.T) {
 	}{
 		{"should return dark theme with base color white", ThemeDark, darkPalette},
 		{"should return light theme with base color black", ThemeLight, lightPalette},
+		{"should return dark colorblind theme with base color white", ThemeDarkColorblind, darkPalette},
+		{"should return light colorblind theme with base color black", ThemeLightColorblind, lightPalette},
 	}
 
 	for _, test := range tests {
@@ -38,13 +40,9 @@ func TestGetPalette(t *testing.T) {
 func TestGetPaletteInvalidTheme(t *testing.T) {
 	const invalid Theme = "invalid"
 
-	defer func() {
-		if r := recover(); r == nil {
-			t.Errorf("GetPalette(%q) should have panicked", invalid)
-		}
-	}()
-
-	GetPalette(invalid)
+	if got := GetPalette(invalid).BaseColor; got != ColorWhite {
+		t.Errorf("GetPalette(%q) = %d, want dark theme base color %d", invalid, got, ColorWhite)
+	}
 }
 
 func TestGetGradientColor(t *testing.T) {
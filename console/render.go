@@ -0,0 +1,118 @@
+package console
+
+import (
+	"os"
+	"runtime"
+	"strings"
+
+	ui "github.com/gizak/termui/v3"
+	tb "github.com/nsf/termbox-go"
+)
+
+type ColorMode string
+
+const (
+	ColorModeNever     ColorMode = "never"
+	ColorMode8         ColorMode = "8"
+	ColorMode16        ColorMode = "16"
+	ColorMode256       ColorMode = "256"
+	ColorModeTrueColor ColorMode = "truecolor"
+)
+
+var colorMode = ColorMode256
+
+// outputModes lists termbox output modes. 8 colors are the first half of the 16 colors
+var outputModes = map[ColorMode]tb.OutputMode{
+	ColorModeNever:     tb.OutputNormal,
+	ColorMode8:         tb.OutputNormal,
+	ColorMode16:        tb.OutputNormal,
+	ColorMode256:       tb.Output256,
+	ColorModeTrueColor: tb.OutputRGB,
+}
+
+// DetectColorMode guesses the terminal color capability from the environment
+func DetectColorMode() ColorMode {
+	return detectColorMode(os.Getenv("NO_COLOR"), os.Getenv("TERM"), os.Getenv("COLORTERM"), runtime.GOOS)
+}
+
+func detectColorMode(noColor, term, colorTerm, goos string) ColorMode {
+
+	term, colorTerm = strings.ToLower(term), strings.ToLower(colorTerm)
+
+	switch {
+	case len(noColor) > 0 || term == "dumb":
+		return ColorModeNever
+	case goos == "windows":
+		// termbox draws with the console API on Windows, which supports 16 colors only
+		return ColorMode16
+	case colorTerm == "truecolor" || colorTerm == "24bit" || strings.HasSuffix(term, "-direct"):
+		return ColorModeTrueColor
+	case strings.Contains(term, "256color"):
+		return ColorMode256
+	case term == "linux" || strings.HasPrefix(term, "vt") || strings.Contains(term, "8color"):
+		return ColorMode8
+	case strings.Contains(term, "16color") || term == "ansi" || term == "cygwin":
+		return ColorMode16
+	default:
+		return ColorMode256
+	}
+}
+
+// Render draws the items the same way as ui.Render does,
+// but converts the colors to the ones the terminal supports
+func Render(items ...ui.Drawable) {
+	for _, item := range items {
+		buffer := ui.NewBuffer(item.GetRect())
+		item.Lock()
+		item.Draw(buffer)
+		item.Unlock()
+		for point, cell := range buffer.CellMap {
+			if point.In(buffer.Rectangle) {
+				tb.SetCell(point.X, point.Y, cell.Rune,
+					toAttribute(cell.Style.Fg, colorMode)|toModifiers(cell.Style.Modifier), toAttribute(cell.Style.Bg, colorMode))
+			}
+		}
+	}
+	tb.Flush()
+}
+
+// toAttribute converts xterm-256 or RGB color to the termbox color of the mode
+func toAttribute(color ui.Color, mode ColorMode) tb.Attribute {
+
+	if color < 0 || mode == ColorModeNever {
+		return tb.ColorDefault
+	}
+
+	switch mode {
+	case ColorModeTrueColor:
+		r, g, b := getRGB(color)
+		return tb.RGBToAttribute(uint8(r), uint8(g), uint8(b))
+	case ColorMode256:
+		if isRGB(color) {
+			r, g, b := getRGB(color)
+			color = GetNearestColor(r, g, b)
+		}
+	case ColorMode16:
+		color = getNearestBasicColor(color, 16)
+	case ColorMode8:
+		color = getNearestBasicColor(color, 8)
+	}
+
+	// zero attribute is the terminal default color
+	return tb.Attribute(color + 1)
+}
+
+// toModifiers maps termui modifiers, since termbox attribute bits are ordered differently
+func toModifiers(modifier ui.Modifier) tb.Attribute {
+	var attribute tb.Attribute
+	if modifier&ui.ModifierBold != 0 {
+		attribute |= tb.AttrBold
+	}
+	if modifier&ui.ModifierUnderline != 0 {
+		attribute |= tb.AttrUnderline
+	}
+	if modifier&ui.ModifierReverse != 0 {
+		attribute |= tb.AttrReverse
+	}
+	return attribute
+}
@@ -0,0 +1,64 @@
+package console
+
+import (
+	"testing"
+
+	ui "github.com/gizak/termui/v3"
+	tb "github.com/nsf/termbox-go"
+)
+
+func TestDetectColorMode(t *testing.T) {
+	tests := []struct {
+		name      string
+		noColor   string
+		term      string
+		colorTerm string
+		goos      string
+		want      ColorMode
+	}{
+		{"should disable colors with NO_COLOR", "1", "xterm-256color", "truecolor", "linux", ColorModeNever},
+		{"should disable colors in dumb terminal", "", "dumb", "", "linux", ColorModeNever},
+		{"should use truecolor with COLORTERM", "", "xterm-256color", "truecolor", "darwin", ColorModeTrueColor},
+		{"should use truecolor with 24bit COLORTERM", "", "screen", "24bit", "linux", ColorModeTrueColor},
+		{"should use truecolor in direct terminal", "", "xterm-direct", "", "linux", ColorModeTrueColor},
+		{"should use 256 colors", "", "screen-256color", "", "linux", ColorMode256},
+		{"should use 8 colors in linux console", "", "linux", "", "linux", ColorMode8},
+		{"should use 8 colors in serial console", "", "vt220", "", "linux", ColorMode8},
+		{"should use 16 colors in 16 color terminal", "", "rxvt-16color", "", "linux", ColorMode16},
+		{"should use 16 colors on windows", "", "", "truecolor", "windows", ColorMode16},
+		{"should use 256 colors by default", "", "xterm", "", "linux", ColorMode256},
+	}
+
+	for _, test := range tests {
+		if got := detectColorMode(test.noColor, test.term, test.colorTerm, test.goos); got != test.want {
+			t.Errorf("%s: detectColorMode() = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestToAttribute(t *testing.T) {
+	tests := []struct {
+		name  string
+		color ui.Color
+		mode  ColorMode
+		want  tb.Attribute
+	}{
+		{"should keep default color", ColorClear, ColorModeTrueColor, tb.ColorDefault},
+		{"should drop colors", ColorOlive, ColorModeNever, tb.ColorDefault},
+		{"should keep xterm-256 color", ColorOlive, ColorMode256, tb.Attribute(ColorOlive + 1)},
+		{"should map rgb to xterm-256 color", NewRGBColor(0x5f, 0xaf, 0xff), ColorMode256, 75 + 1},
+		{"should map xterm-256 color to rgb", ColorDeepSkyBlue, ColorModeTrueColor, tb.RGBToAttribute(0, 0xaf, 0xff)},
+		{"should keep rgb", NewRGBColor(1, 2, 3), ColorModeTrueColor, tb.RGBToAttribute(1, 2, 3)},
+		{"should map to 16 colors", ui.Color(227), ColorMode16, tb.Attribute(11 + 1)},
+		{"should map to the same 8 colors", ui.Color(227), ColorMode8, tb.Attribute(3 + 1)},
+		{"should keep basic color in 16 colors", ColorWhite, ColorMode16, tb.Attribute(ColorWhite + 1)},
+		{"should map to 8 colors", ColorDeepPink, ColorMode8, tb.Attribute(5 + 1)},
+		{"should map bright color to 8 colors", ColorWhite, ColorMode8, tb.Attribute(7 + 1)},
+	}
+
+	for _, test := range tests {
+		if got := toAttribute(test.color, test.mode); got != test.want {
+			t.Errorf("%s: toAttribute(%d, %q) = %d, want %d", test.name, test.color, test.mode, got, test.want)
+		}
+	}
+}
@@ -1,8 +1,11 @@
 package console
 
 const (
-	SignalResize = "<Resize>"
-	SignalClick  = "<MouseLeft>"
+	SignalResize       = "<Resize>"
+	SignalClick        = "<MouseLeft>"
+	SignalMouseRelease = "<MouseRelease>"
+	SignalWheelUp      = "<MouseWheelUp>"
+	SignalWheelDown    = "<MouseWheelDown>"
 )
 
 const (
@@ -17,4 +20,14 @@ const (
 	KeyDown   = "<Down>"
 	KeyEnter  = "<Enter>"
 	KeyEsc    = "<Escape>"
+	KeyAdd1   = "a"
+	KeyAdd2   = "A"
+	KeyHelp   = "?"
+	KeyStats  = "d"
+)
+
+const (
+	KeySpace      = "<Space>"
+	KeyBackspace1 = "<Backspace>"
+	KeyBackspace2 = "<C-<Backspace>>"
 )
@@ -0,0 +1,193 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"github.com/lunixbochs/vtclean"
+	"github.com/sqshq/sampler/config"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	markerPrefix = "SAMPLER_MARKER"
+	// initTimeout is the default timeout of the init steps, which may include a slow login
+	initTimeout = 30 * time.Second
+)
+
+var completionsCount int32
+
+var errSessionClosed = errors.New("interactive shell session is closed")
+
+// completion detects the end of a command output in the interactive shell,
+// either by a unique marker, printed after each command, or by a shell prompt
+type completion struct {
+	markerScript string
+	prompt       *regexp.Regexp
+	markers      *regexp.Regexp
+	id           int32
+	counter      int32
+	echo         bool
+	timeout      time.Duration // of a sample, the sampling rate by default
+	initTimeout  time.Duration
+}
+
+func newCompletion(cfg *config.CompletionConfig, echo bool, rateMs int) *completion {
+
+	if cfg == nil {
+		return nil
+	}
+
+	id := atomic.AddInt32(&completionsCount, 1)
+	c := &completion{
+		id:          id,
+		echo:        echo,
+		markers:     regexp.MustCompile(fmt.Sprintf("%s_%d_[0-9]+", markerPrefix, id)),
+		timeout:     time.Duration(rateMs) * time.Millisecond,
+		initTimeout: initTimeout,
+	}
+
+	if cfg.TimeoutMs != nil {
+		c.timeout = time.Duration(*cfg.TimeoutMs) * time.Millisecond
+		c.initTimeout = c.timeout
+	}
+
+	if cfg.Marker != nil {
+		c.markerScript = *cfg.Marker
+	} else {
+		c.prompt = regexp.MustCompile(*cfg.Prompt)
+	}
+
+	return c
+}
+
+// wrap returns the script to be written to the shell stdin,
+// followed by the marker script with a new unique marker
+func (c *completion) wrap(script string) string {
+	return fmt.Sprintf(" %s\n%s", script, c.next())
+}
+
+// next returns the marker script with a new unique marker,
+// or nothing if the completion is detected by a prompt
+func (c *completion) next() string {
+
+	if c.prompt != nil {
+		return ""
+	}
+
+	atomic.AddInt32(&c.counter, 1)
+
+	return fmt.Sprintf(" %s\n", c.getMarkerScript())
+}
+
+func (c *completion) getMarker() string {
+	return fmt.Sprintf("%s_%d_%d", markerPrefix, c.id, atomic.LoadInt32(&c.counter))
+}
+
+func (c *completion) getMarkerScript() string {
+	return strings.TrimSpace(strings.Replace(c.markerScript, config.MarkerPlaceholder, c.getMarker(), -1))
+}
+
+// await reads the shell output until the completion is detected. Output lines,
+// which contain the script itself (e.g. terminal echo), are omitted from the result
+func (c *completion) await(script string, stdout <-chan string, stderr <-chan string, timeout time.Duration) (string, error) {
+
+	deadline := time.After(timeout)
+
+	var output string
+	var errorText strings.Builder
+
+	for {
+		select {
+		case out, ok := <-stdout:
+			if !ok {
+				return "", errSessionClosed
+			}
+			output += out
+			if result, done := c.detect(&output); done {
+				if errorText.Len() > 0 {
+					return "", errors.New(errorText.String())
+				}
+				return c.clean(result, script), nil
+			}
+		case e, ok := <-stderr:
+			if !ok {
+				stderr = nil
+				continue
+			}
+			if len(e) > 0 {
+				errorText.WriteString(e)
+				errorText.WriteString("\n")
+			}
+		case <-deadline:
+			return "", fmt.Errorf("command completion wasn't detected in %v", timeout)
+		}
+	}
+}
+
+// detect checks whether the output is complete and returns the command output.
+// Output of the previously timed out commands is dropped
+func (c *completion) detect(output *string) (string, bool) {
+
+	if c.prompt != nil {
+		lastLineIndex := strings.LastIndex(*output, "\n")
+		lastLine := vtclean.Clean((*output)[lastLineIndex+1:], false)
+		if c.prompt.MatchString(lastLine) {
+			result := ""
+			if lastLineIndex >= 0 {
+				result = (*output)[:lastLineIndex]
+			}
+			*output = ""
+			return result, true
+		}
+		return "", false
+	}
+
+	marker := c.getMarker()
+	markerScript := c.getMarkerScript()
+
+	var result strings.Builder
+	lines := strings.SplitAfter(*output, "\n")
+
+	for i, line := range lines {
+
+		if !strings.HasSuffix(line, "\n") {
+			break
+		}
+
+		if strings.Contains(line, markerScript) {
+			continue
+		}
+
+		found := c.markers.FindString(line)
+		if found == marker {
+			*output = strings.Join(lines[i+1:], "")
+			return result.String(), true
+		} else if len(found) > 0 {
+			result.Reset()
+			continue
+		}
+
+		result.WriteString(line)
+	}
+
+	return "", false
+}
+
+func (c *completion) clean(output string, script string) string {
+
+	var builder strings.Builder
+
+	for _, line := range strings.Split(vtclean.Clean(output, false), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if len(line) == 0 || (c.echo && strings.Contains(line, strings.TrimSpace(script))) {
+			continue
+		}
+		builder.WriteString(line)
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,98 @@
+package data
+
+import (
+	"fmt"
+	"github.com/sqshq/sampler/config"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompletion_detect(t *testing.T) {
+
+	marker := "echo $marker"
+	prompt := `^mysql> $`
+
+	tests := []struct {
+		name   string
+		cfg    config.CompletionConfig
+		calls  int
+		output string
+		want   string
+		done   bool
+	}{
+		{"should wait for marker", config.CompletionConfig{Marker: &marker}, 1, "42\n", "", false},
+		{"should detect marker", config.CompletionConfig{Marker: &marker}, 1, "42\nSAMPLER_MARKER_ID_1\n", "42\n", true},
+		{"should skip echoed marker script", config.CompletionConfig{Marker: &marker}, 1, "$ echo SAMPLER_MARKER_ID_1\n42\nSAMPLER_MARKER_ID_1\n", "42\n", true},
+		{"should drop output of timed out command", config.CompletionConfig{Marker: &marker}, 2, "1\nSAMPLER_MARKER_ID_1\n2\nSAMPLER_MARKER_ID_2\n", "2\n", true},
+		{"should wait for prompt", config.CompletionConfig{Prompt: &prompt}, 1, "SELECT 1;\n1\nmys", "", false},
+		{"should detect prompt", config.CompletionConfig{Prompt: &prompt}, 1, "SELECT 1;\n1\nmysql> ", "SELECT 1;\n1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCompletion(&tt.cfg, false, 1000)
+			for i := 0; i < tt.calls; i++ {
+				c.next()
+			}
+			output := fillMarkers(tt.output, c.id)
+			got, done := c.detect(&output)
+			if done != tt.done || got != tt.want {
+				t.Errorf("detect() = %q, %v, want %q, %v", got, done, tt.want, tt.done)
+			}
+		})
+	}
+}
+
+func TestBasicInteractiveShell_completion(t *testing.T) {
+
+	marker, timeout := "echo $marker", 2000
+	item := &Item{
+		initScripts:  []string{"sh", "x=41", "sleep 0.3"},
+		sampleScript: "sleep 0.3; echo $((x+1))",
+		rateMs:       100,
+		completion:   &config.CompletionConfig{Marker: &marker, TimeoutMs: &timeout},
+	}
+
+	shell := &BasicInteractiveShell{item: item, timeout: 75 * time.Millisecond}
+	if err := shell.init(); err != nil {
+		t.Fatalf("init() error = %v", err)
+	}
+	defer func() { _ = shell.cmd.Process.Kill() }()
+
+	for i := 0; i < 2; i++ {
+		got, err := shell.execute()
+		if err != nil {
+			t.Fatalf("execute() error = %v", err)
+		}
+		if got != "42\n" {
+			t.Errorf("execute() = %q, want %q", got, "42\n")
+		}
+	}
+}
+
+func TestNewCompletion_timeout(t *testing.T) {
+
+	marker, timeout := "echo $marker", 500
+
+	tests := []struct {
+		name     string
+		cfg      config.CompletionConfig
+		want     time.Duration
+		wantInit time.Duration
+	}{
+		{"should wait for a sample till the next one", config.CompletionConfig{Marker: &marker}, time.Second, initTimeout},
+		{"should use configured timeout", config.CompletionConfig{Marker: &marker, TimeoutMs: &timeout}, 500 * time.Millisecond, 500 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCompletion(&tt.cfg, false, 1000)
+			if c.timeout != tt.want || c.initTimeout != tt.wantInit {
+				t.Errorf("newCompletion() timeouts = %v, %v, want %v, %v", c.timeout, c.initTimeout, tt.want, tt.wantInit)
+			}
+		})
+	}
+}
+
+func fillMarkers(output string, id int32) string {
+	return strings.Replace(output, "_ID_", fmt.Sprintf("_%d_", id), -1)
+}
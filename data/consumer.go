@@ -2,28 +2,148 @@ package data
 
 import (
 	ui "github.com/gizak/termui/v3"
+	"reflect"
 	"strings"
+	"sync"
 )
 
+// Consumer receives samples, alerts and commands of a component. Sending never blocks: when the
+// component is behind, the oldest queued samples are dropped, so it catches up with the latest ones.
+// Only the latest alert is kept, since a new one replaces the previous anyway. Commands change
+// the component state, so they are queued instead, and are never dropped
 type Consumer struct {
 	SampleChannel  chan *Sample
 	AlertChannel   chan *Alert
 	CommandChannel chan *Command
-	Alert          *Alert
+	alert          *Alert
+	commands       []*Command
+	drops          Drops
+	mutex          sync.Mutex
+}
+
+// Drops counts the values, which were replaced by the newer ones before the component received them
+type Drops struct {
+	Samples int
+	Alerts  int
+}
+
+// PushSample is called by the sampler, which is never blocked by the component.
+// Returns the number of queued samples, which were dropped to make room for the new one
+func (c *Consumer) PushSample(sample *Sample) int {
+	dropped := push(c.SampleChannel, sample)
+	if dropped > 0 {
+		c.count(&c.drops.Samples, dropped)
+	}
+	return dropped
+}
+
+// PushAlert replaces the alert, which is not yet received by the component
+func (c *Consumer) PushAlert(alert *Alert) {
+	if dropped := push(c.AlertChannel, alert); dropped > 0 {
+		c.count(&c.drops.Alerts, dropped)
+	}
+}
+
+// ResetAlert hides the current alert without blocking, e.g. when called from the UI goroutine
+func (c *Consumer) ResetAlert() {
+	c.PushAlert(nil)
+}
+
+// PushCommand is called from the UI goroutine, which is never blocked by the component. When the
+// channel is full, the command waits in the queue along with the next ones, so the order is kept
+func (c *Consumer) PushCommand(command *Command) {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if len(c.commands) == 0 {
+		select {
+		case c.CommandChannel <- command:
+			return
+		default:
+			go c.sendCommands()
+		}
+	}
+
+	c.commands = append(c.commands, command)
+}
+
+// sendCommands delivers the queued commands one by one, until the queue is empty. The command
+// is kept in the queue until it's received, so the new ones are queued after it
+func (c *Consumer) sendCommands() {
+	for {
+		c.mutex.Lock()
+		if len(c.commands) == 0 {
+			c.mutex.Unlock()
+			return
+		}
+		command := c.commands[0]
+		c.mutex.Unlock()
+
+		c.CommandChannel <- command
+
+		c.mutex.Lock()
+		c.commands = c.commands[1:]
+		c.mutex.Unlock()
+	}
+}
+
+func (c *Consumer) GetDrops() Drops {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.drops
+}
+
+func (c *Consumer) count(counter *int, n int) {
+	c.mutex.Lock()
+	*counter += n
+	c.mutex.Unlock()
+}
+
+// SetAlert is called by the component, when the alert is received
+func (c *Consumer) SetAlert(alert *Alert) {
+	c.mutex.Lock()
+	c.alert = alert
+	c.mutex.Unlock()
+}
+
+// GetAlert returns the alert to render, while it's updated by the component goroutine
+func (c *Consumer) GetAlert() *Alert {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.alert
 }
 
 func (c *Consumer) HandleConsumeSuccess() {
-	if c.Alert != nil && c.Alert.Recoverable {
-		c.Alert = nil
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.alert != nil && c.alert.Recoverable {
+		c.alert = nil
 	}
 }
 
+// HandleConsumeFailure is called by the component goroutine, which receives the alert itself,
+// so it must not block on the full alert channel
 func (c *Consumer) HandleConsumeFailure(title string, err error, sample *Sample) {
-	c.AlertChannel <- &Alert{
+	c.PushAlert(&Alert{
 		Title:       strings.ToUpper(title),
-		Text:        err.Error(),
+		Text:        getErrorMessage(err),
 		Color:       sample.Color,
 		Recoverable: true,
+	})
+}
+
+// push sends the value to the channel without blocking. When the channel buffer is full, the oldest
+// value is dropped, so the receiver gets the latest ones. Returns the number of dropped values
+func push(channel interface{}, value interface{}) int {
+	ch, v := reflect.ValueOf(channel), reflect.ValueOf(value)
+	for dropped := 0; ; {
+		if ch.TrySend(v) {
+			return dropped
+		}
+		if _, ok := ch.TryRecv(); ok {
+			dropped++
+		}
 	}
 }
 
@@ -48,7 +168,7 @@ type Command struct {
 func NewConsumer() *Consumer {
 	return &Consumer{
 		SampleChannel:  make(chan *Sample, 10),
-		AlertChannel:   make(chan *Alert, 10),
+		AlertChannel:   make(chan *Alert, 1),
 		CommandChannel: make(chan *Command, 10),
 	}
 }
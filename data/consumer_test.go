@@ -3,7 +3,9 @@ package data
 import (
 	"errors"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestConsumer_HandleConsumeSuccess(t *testing.T) {
@@ -19,10 +21,10 @@ func TestConsumer_HandleConsumeSuccess(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := NewConsumer()
-			c.Alert = tt.existingAlert
+			c.SetAlert(tt.existingAlert)
 			c.HandleConsumeSuccess()
-			if !reflect.DeepEqual(c.Alert, tt.expectedAlert) {
-				t.Errorf("unexpected alert state after HandleConsumeSuccess(), want %v, got %v", tt.expectedAlert, c.Alert)
+			if !reflect.DeepEqual(c.GetAlert(), tt.expectedAlert) {
+				t.Errorf("unexpected alert state after HandleConsumeSuccess(), want %v, got %v", tt.expectedAlert, c.GetAlert())
 			}
 		})
 	}
@@ -79,7 +81,7 @@ func TestNewConsumer(t *testing.T) {
 		{
 			"alert is nil after creation",
 			func(c *Consumer) bool {
-				return c.Alert == nil
+				return c.GetAlert() == nil
 			},
 		},
 		{
@@ -109,3 +111,104 @@ func TestNewConsumer(t *testing.T) {
 		})
 	}
 }
+
+func TestConsumer_PushSample(t *testing.T) {
+
+	c := NewConsumer()
+
+	dropped := 0
+	for i := 0; i < 15; i++ {
+		dropped += c.PushSample(&Sample{Label: "test", Value: string(rune('a' + i))})
+	}
+
+	if dropped != 5 || c.GetDrops().Samples != 5 {
+		t.Errorf("PushSample() dropped %d, drops %+v, want 5 dropped samples", dropped, c.GetDrops())
+	}
+
+	// the oldest samples are dropped, so the latest ones are received
+	if got := <-c.SampleChannel; got.Value != "f" {
+		t.Errorf("first sample after drops = %v, want f", got.Value)
+	}
+}
+
+func TestConsumer_PushAlert(t *testing.T) {
+
+	c := NewConsumer()
+
+	// nobody receives the alerts, but neither the new alert nor the reset is blocked
+	c.PushAlert(&Alert{Title: "first"})
+	c.PushAlert(&Alert{Title: "second"})
+	c.ResetAlert()
+
+	if got := <-c.AlertChannel; got != nil {
+		t.Errorf("alert after reset = %v, want nil", got)
+	}
+	if got := c.GetDrops().Alerts; got != 2 {
+		t.Errorf("GetDrops().Alerts = %d, want 2", got)
+	}
+}
+
+func TestConsumer_PushCommand(t *testing.T) {
+
+	c := NewConsumer()
+
+	// nobody receives the commands yet, but they are queued without blocking
+	for i := 0; i < 25; i++ {
+		c.PushCommand(&Command{Type: "test", Value: i})
+	}
+
+	// commands are never dropped, and are received in order
+	for i := 0; i < 25; i++ {
+		select {
+		case command := <-c.CommandChannel:
+			if command.Value != i {
+				t.Fatalf("command %d value = %v, want %d", i, command.Value, i)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("command %d is not received", i)
+		}
+	}
+}
+
+func TestConsumer_concurrentPush(t *testing.T) {
+
+	c := NewConsumer()
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	// the component receives values, and updates the alert, as it's done in its goroutine
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-c.SampleChannel:
+				c.HandleConsumeSuccess()
+			case alert := <-c.AlertChannel:
+				c.SetAlert(alert)
+			case <-c.CommandChannel:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				c.PushSample(&Sample{Label: "test", Value: "1"})
+				c.PushAlert(&Alert{Title: "test", Recoverable: true})
+				c.ResetAlert()
+				c.PushCommand(&Command{Type: "test"})
+				c.GetAlert()
+				c.GetDrops()
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(stop)
+	<-done
+}
@@ -13,9 +13,10 @@ func getErrorMessage(err error) string {
 	if ok {
 		stderr := string(exitErr.Stderr)
 		if len(stderr) != 0 {
-			message = fmt.Sprintf("%.200s", stderr)
+			// secrets are masked before the truncation, which could leave a part of them unmasked
+			return fmt.Sprintf("%.200s", maskSecrets(stderr))
 		}
 	}
 
-	return message
+	return maskSecrets(message)
 }
@@ -8,25 +8,33 @@ import (
 	"io"
 	"os/exec"
 	"strings"
+	"sync"
 	"time"
 )
 
+const outputBufferSize = 100
+
 // BasicInteractiveShell represents non-PTY interactive shell sampling metadata
 type BasicInteractiveShell struct {
-	item      *Item
-	variables []string
-	stdoutCh  chan string
-	stderrCh  chan string
-	stdin     io.WriteCloser
-	cmd       *exec.Cmd
-	errCount  int
-	timeout   time.Duration
+	item       *Item
+	variables  []string
+	stdoutCh   chan string
+	stderrCh   chan string
+	stdin      io.WriteCloser
+	cmd        *exec.Cmd
+	errCount   int
+	timeout    time.Duration
+	completion *completion
+	exited     chan struct{}
+	exitErr    error
 }
 
-func (s *BasicInteractiveShell) init() error {
+func (s *BasicInteractiveShell) init() (err error) {
 
-	cmd := exec.Command("sh", "-c", s.item.initScripts[0])
-	enrichEnvVariables(cmd, s.variables)
+	cmd, input, err := s.item.newCommand(s.item.initScripts[0], s.variables, false)
+	if err != nil {
+		return err
+	}
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -43,35 +51,86 @@ func (s *BasicInteractiveShell) init() error {
 		return err
 	}
 
-	stdoutScanner := bufio.NewScanner(stdout)
-	stderrScanner := bufio.NewScanner(stderr)
+	s.completion = newCompletion(s.item.completion, false, s.item.rateMs)
+	s.stdoutCh = make(chan string, outputBufferSize)
+	s.stderrCh = make(chan string, outputBufferSize)
+	s.exited = make(chan struct{})
+	s.stdin = stdin
+	s.cmd = cmd
 
-	stdoutCh := make(chan string)
-	stderrCh := make(chan string)
+	err = cmd.Start()
+	if err != nil {
+		return err
+	}
 
-	go func() {
-		for stdoutScanner.Scan() {
-			stdoutCh <- stdoutScanner.Text()
-			stderrCh <- stderrScanner.Text()
+	// the session isn't stored on the item if the init fails, so it's closed here
+	defer func() {
+		if err != nil {
+			s.restart()
 		}
 	}()
 
-	s.stdoutCh = stdoutCh
-	s.stderrCh = stderrCh
-	s.stdin = stdin
-	s.cmd = cmd
-
-	err = cmd.Start()
+	_, err = io.WriteString(stdin, input)
 	if err != nil {
 		return err
 	}
 
+	lineEnding := ""
+	if s.completion != nil {
+		lineEnding = "\n"
+	}
+
+	// stdout and stderr are read independently, the session is
+	// considered to be exited after both of them are closed
+	readers := &sync.WaitGroup{}
+	readers.Add(2)
+	go readLines(stdout, s.stdoutCh, lineEnding, readers)
+	go readLines(stderr, s.stderrCh, "", readers)
+
+	go func() {
+		readers.Wait()
+		s.exitErr = cmd.Wait()
+		close(s.exited)
+	}()
+
+	if s.completion != nil {
+		return s.awaitInitScripts()
+	}
+
 	for i := 1; i < len(s.item.initScripts); i++ {
 		_, err := io.WriteString(s.stdin, fmt.Sprintf(" %s\n", s.item.initScripts[i]))
 		if err != nil {
 			return err
 		}
-		time.Sleep(startupTimeout) // TODO wait until cmd complete
+		time.Sleep(startupTimeout)
+	}
+
+	// discard startup output, e.g. greeting banner
+	time.Sleep(startupTimeout)
+	s.drain()
+
+	return nil
+}
+
+// awaitInitScripts executes init steps one after another, waiting for completion of each
+func (s *BasicInteractiveShell) awaitInitScripts() error {
+
+	_, err := io.WriteString(s.stdin, s.completion.next())
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(s.item.initScripts); i++ {
+		if i > 0 {
+			_, err = io.WriteString(s.stdin, s.completion.wrap(s.item.initScripts[i]))
+			if err != nil {
+				return err
+			}
+		}
+		_, err = s.completion.await(s.item.initScripts[i], s.stdoutCh, s.stderrCh, s.completion.initTimeout)
+		if err != nil {
+			return s.checkExit(err)
+		}
 	}
 
 	return nil
@@ -83,34 +142,52 @@ func (s *BasicInteractiveShell) execute() (string, error) {
 		return "", nil
 	}
 
-	_, err := io.WriteString(s.stdin, fmt.Sprintf(" %s\n", s.item.sampleScript))
+	if err := s.checkExit(nil); err != nil {
+		return "", err
+	}
+
+	script := fmt.Sprintf(" %s\n", s.item.sampleScript)
+	if s.completion != nil {
+		script = s.completion.wrap(s.item.sampleScript)
+	}
+
+	_, err := io.WriteString(s.stdin, script)
 	if err != nil {
 		s.errCount++
 		if s.errCount > errorThreshold {
-			_ = s.cmd.Wait()
-			s.item.basicShell = nil // restart session
+			s.restart()
 		}
-		return "", fmt.Errorf("failed to execute command: %s", err)
+		return "", s.checkExit(fmt.Errorf("failed to execute command: %s", err))
 	}
 
-	timeout := make(chan bool, 1)
+	if s.completion != nil {
+		sample, err := s.completion.await(s.item.sampleScript, s.stdoutCh, s.stderrCh, s.completion.timeout)
+		return sample, s.checkExit(err)
+	}
 
-	go func() {
-		time.Sleep(s.timeout)
-		timeout <- true
-	}()
+	timeout := time.After(s.timeout)
+	stdoutCh := s.stdoutCh
+	stderrCh := s.stderrCh
 
 	var resultText strings.Builder
 	var errorText strings.Builder
 
 	for {
 		select {
-		case stdout := <-s.stdoutCh:
+		case stdout, ok := <-stdoutCh:
+			if !ok {
+				stdoutCh = nil
+				continue
+			}
 			if len(stdout) > 0 {
 				resultText.WriteString(stdout)
 				resultText.WriteString("\n")
 			}
-		case stderr := <-s.stderrCh:
+		case stderr, ok := <-stderrCh:
+			if !ok {
+				stderrCh = nil
+				continue
+			}
 			if len(stderr) > 0 {
 				errorText.WriteString(stderr)
 				errorText.WriteString("\n")
@@ -119,7 +196,81 @@ func (s *BasicInteractiveShell) execute() (string, error) {
 			if errorText.Len() > 0 {
 				return "", errors.New(errorText.String())
 			}
-			return s.item.transform(vtclean.Clean(resultText.String(), false))
+			if resultText.Len() == 0 {
+				if err := s.checkExit(nil); err != nil {
+					return "", err
+				}
+			}
+			return vtclean.Clean(resultText.String(), false), nil
+		}
+	}
+}
+
+// checkExit returns an error with the exit reason and the remaining stderr output,
+// if the session has exited. The session is restarted on the next sample then
+func (s *BasicInteractiveShell) checkExit(err error) error {
+
+	if err == errSessionClosed {
+		select {
+		case <-s.exited:
+		case <-time.After(startupTimeout):
 		}
 	}
+
+	select {
+	case <-s.exited:
+	default:
+		return err
+	}
+
+	var stderr strings.Builder
+	for line := range s.stderrCh {
+		if len(line) > 0 {
+			stderr.WriteString("\n")
+			stderr.WriteString(line)
+		}
+	}
+
+	s.restart()
+
+	reason := "exit status 0"
+	if s.exitErr != nil {
+		reason = s.exitErr.Error()
+	}
+
+	return fmt.Errorf("interactive shell session exited with %s%s", reason, stderr.String())
+}
+
+func (s *BasicInteractiveShell) drain() {
+	for {
+		select {
+		case _, ok := <-s.stdoutCh:
+			if !ok {
+				return
+			}
+		case _, ok := <-s.stderrCh:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (s *BasicInteractiveShell) restart() {
+	_ = s.stdin.Close()
+	if s.cmd.Process != nil {
+		_ = s.cmd.Process.Kill()
+	}
+	s.item.basicShell = nil
+}
+
+func readLines(reader io.Reader, channel chan string, lineEnding string, wg *sync.WaitGroup) {
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		channel <- scanner.Text() + lineEnding
+	}
+	close(channel)
+	wg.Done()
 }
@@ -0,0 +1,110 @@
+package data
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeRepl emulates an interactive shell, which writes
+// to stdout and stderr independently and might exit on request
+const fakeRepl = `#!/bin/sh
+echo "fake repl started" >&2
+while read -r cmd; do
+  case "$cmd" in
+    err) echo "first error line" >&2; echo "second error line" >&2 ;;
+    exit) echo "bye" >&2; exit 3 ;;
+    *) echo "value $cmd" ;;
+  esac
+done
+`
+
+func newFakeReplItem(t *testing.T) *Item {
+
+	dir, err := ioutil.TempDir("", "sampler")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	script := filepath.Join(dir, "repl.sh")
+	if err = ioutil.WriteFile(script, []byte(fakeRepl), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	return &Item{
+		label:       "fake",
+		initScripts: []string{script},
+		rateMs:      400,
+	}
+}
+
+func TestBasicInteractiveShell_stdout(t *testing.T) {
+
+	item := newFakeReplItem(t)
+	item.sampleScript = "42"
+
+	for i := 0; i < 3; i++ {
+		got, err := item.nextValue(nil)
+		if err != nil {
+			t.Fatalf("nextValue() error = %v", err)
+		}
+		if got != "value 42\n" {
+			t.Errorf("nextValue() = %q, want %q", got, "value 42\n")
+		}
+	}
+
+	item.basicShell.(*BasicInteractiveShell).restart()
+}
+
+func TestBasicInteractiveShell_stderrOnly(t *testing.T) {
+
+	item := newFakeReplItem(t)
+	item.sampleScript = "err"
+
+	_, err := item.nextValue(nil)
+	if err == nil {
+		t.Fatalf("nextValue() expected an error")
+	}
+	if !strings.Contains(err.Error(), "first error line\nsecond error line") {
+		t.Errorf("nextValue() error = %q, want both stderr lines", err.Error())
+	}
+
+	item.basicShell.(*BasicInteractiveShell).restart()
+}
+
+func TestBasicInteractiveShell_restartOnExit(t *testing.T) {
+
+	item := newFakeReplItem(t)
+	item.sampleScript = "exit"
+
+	_, err := item.nextValue(nil)
+	if err == nil || !strings.Contains(err.Error(), "bye") {
+		t.Fatalf("nextValue() error = %v, want stderr output", err)
+	}
+
+	time.Sleep(startupTimeout)
+
+	_, err = item.nextValue(nil)
+	if err == nil || !strings.Contains(err.Error(), "exit status 3") {
+		t.Fatalf("nextValue() error = %v, want exit status", err)
+	}
+	if item.basicShell != nil {
+		t.Fatalf("session should be reset after exit")
+	}
+
+	item.sampleScript = "42"
+
+	got, err := item.nextValue(nil)
+	if err != nil {
+		t.Fatalf("nextValue() after restart error = %v", err)
+	}
+	if got != "value 42\n" {
+		t.Errorf("nextValue() after restart = %q, want %q", got, "value 42\n")
+	}
+
+	item.basicShell.(*BasicInteractiveShell).restart()
+}
@@ -15,27 +15,57 @@ import (
 
 // PtyInteractiveShell represents PTY interactive shell sampling metadata
 type PtyInteractiveShell struct {
-	item      *Item
-	variables []string
-	cmd       *exec.Cmd
-	file      io.WriteCloser
-	ch        chan string
-	errCount  int
-	timeout   time.Duration
+	item       *Item
+	variables  []string
+	cmd        *exec.Cmd
+	file       io.WriteCloser
+	ch         chan string
+	errCount   int
+	timeout    time.Duration
+	completion *completion
 }
 
-func (s *PtyInteractiveShell) init() error {
+func (s *PtyInteractiveShell) init() (err error) {
 
-	cmd := exec.Command("sh", "-c", s.item.initScripts[0])
-	enrichEnvVariables(cmd, s.variables)
+	cmd, input, err := s.item.newCommand(s.item.initScripts[0], s.variables, true)
+	if err != nil {
+		return err
+	}
 
 	file, err := pty.Start(cmd)
 	if err != nil {
 		return err
 	}
 
-	scanner := bufio.NewScanner(file)
 	channel := make(chan string)
+	s.completion = newCompletion(s.item.completion, true, s.item.rateMs)
+
+	s.cmd = cmd
+	s.file = file
+	s.ch = channel
+
+	// the session isn't stored on the item if the init fails, so it's closed here
+	defer func() {
+		if err != nil {
+			s.close()
+		}
+	}()
+
+	if len(input) > 0 {
+		if err = awaitSshReady(file); err != nil {
+			return err
+		}
+		if _, err = io.WriteString(file, input); err != nil {
+			return err
+		}
+	}
+
+	if s.completion != nil {
+		go readChunks(file, channel)
+		return s.awaitInitScripts()
+	}
+
+	scanner := bufio.NewScanner(file)
 
 	go func() {
 		for scanner.Scan() {
@@ -43,10 +73,6 @@ func (s *PtyInteractiveShell) init() error {
 		}
 	}()
 
-	s.cmd = cmd
-	s.file = file
-	s.ch = channel
-
 	_, err = file.Read(make([]byte, 4096))
 	if err != nil {
 		return err
@@ -59,7 +85,37 @@ func (s *PtyInteractiveShell) init() error {
 		if err != nil {
 			return err
 		}
-		time.Sleep(startupTimeout) // TODO wait until cmd complete
+	}
+
+	return nil
+}
+
+func (s *PtyInteractiveShell) close() {
+	_ = s.file.Close()
+	if s.cmd.Process != nil {
+		_ = s.cmd.Process.Kill()
+	}
+}
+
+// awaitInitScripts executes init steps one after another, waiting for completion of each
+func (s *PtyInteractiveShell) awaitInitScripts() error {
+
+	_, err := io.WriteString(s.file, s.completion.next())
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(s.item.initScripts); i++ {
+		if i > 0 {
+			_, err = io.WriteString(s.file, s.completion.wrap(s.item.initScripts[i]))
+			if err != nil {
+				return err
+			}
+		}
+		_, err = s.completion.await(s.item.initScripts[i], s.ch, nil, s.completion.initTimeout)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -67,7 +123,12 @@ func (s *PtyInteractiveShell) init() error {
 
 func (s *PtyInteractiveShell) execute() (string, error) {
 
-	_, err := io.WriteString(s.file, fmt.Sprintf(" %s\n", s.item.sampleScript))
+	script := fmt.Sprintf(" %s\n", s.item.sampleScript)
+	if s.completion != nil {
+		script = s.completion.wrap(s.item.sampleScript)
+	}
+
+	_, err := io.WriteString(s.file, script)
 	if err != nil {
 		s.errCount++
 		if s.errCount > errorThreshold {
@@ -78,6 +139,11 @@ func (s *PtyInteractiveShell) execute() (string, error) {
 		return "", fmt.Errorf("failed to execute command: %s", err)
 	}
 
+	if s.completion != nil {
+		sample, err := s.completion.await(s.item.sampleScript, s.ch, nil, s.completion.timeout)
+		return strings.TrimSpace(sample), err
+	}
+
 	softTimeout := make(chan bool, 1)
 	hardTimeout := make(chan bool, 1)
 
@@ -114,9 +180,22 @@ await:
 		}
 	}
 
-	sample := strings.TrimSpace(builder.String())
+	return strings.TrimSpace(builder.String()), nil
+}
 
-	return s.item.transform(sample)
+// readChunks reads raw output, since shell prompt is usually not followed by a line break
+func readChunks(reader io.Reader, channel chan string) {
+	buffer := make([]byte, 4096)
+	for {
+		n, err := reader.Read(buffer)
+		if n > 0 {
+			channel <- string(buffer[:n])
+		}
+		if err != nil {
+			close(channel)
+			return
+		}
+	}
 }
 
 func (s *PtyInteractiveShell) getAwaitTimeout() time.Duration {
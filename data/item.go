@@ -6,21 +6,29 @@ import (
 	"github.com/sqshq/sampler/config"
 	"os"
 	"os/exec"
+	"strings"
 	"time"
 )
 
 const errorThreshold = 10
 
 type Item struct {
-	label           string
-	initScripts     []string
-	sampleScript    string
-	transformScript *string
-	color           *ui.Color
-	rateMs          int
-	pty             bool
-	basicShell      InteractiveShell
-	ptyShell        InteractiveShell
+	label        string
+	initScripts  []string
+	sampleScript string
+	transforms   []Transform
+	completion   *config.CompletionConfig
+	ssh          *string
+	statsd       *string
+	prometheus   *prometheusQuery
+	sql          *sqlQuery
+	color        *ui.Color
+	rateMs       int
+	pty          bool
+	basicShell   InteractiveShell
+	ptyShell     InteractiveShell
+	stats        itemStats
+	sampling     flag
 }
 
 func NewItems(cfgs []config.Item, rateMs int) []*Item {
@@ -28,14 +36,27 @@ func NewItems(cfgs []config.Item, rateMs int) []*Item {
 	items := make([]*Item, 0)
 
 	for _, i := range cfgs {
+		sampleScript := ""
+		if i.SampleScript != nil {
+			sampleScript = *i.SampleScript
+		}
 		item := &Item{
-			label:           *i.Label,
-			sampleScript:    *i.SampleScript,
-			initScripts:     getInitScripts(i),
-			transformScript: i.TransformScript,
-			color:           i.Color,
-			rateMs:          rateMs,
-			pty:             *i.Pty,
+			label:        *i.Label,
+			sampleScript: sampleScript,
+			initScripts:  getInitScripts(i),
+			transforms:   NewTransforms(i.Transforms, i.TransformScript),
+			completion:   i.Completion,
+			ssh:          i.Ssh,
+			statsd:       i.Statsd,
+			color:        i.Color,
+			rateMs:       rateMs,
+			pty:          *i.Pty,
+		}
+		if i.Prometheus != nil {
+			item.prometheus = newPrometheusQuery(*i.Prometheus)
+		}
+		if i.Sql != nil {
+			item.sql = newSqlQuery(*i.Sql)
 		}
 		items = append(items, item)
 	}
@@ -51,19 +72,65 @@ func (i *Item) nextValue(variables []string) (string, error) {
 		}
 	}
 
-	if i.basicShell != nil {
-		return i.basicShell.execute()
+	var sample string
+	var err error
+
+	if i.statsd != nil {
+		sample, err = getStatsdValue(*i.statsd)
+	} else if i.prometheus != nil {
+		// a target is scraped once per tick, even if it's shared by several items
+		sample, err = i.prometheus.value(time.Duration(i.rateMs) * time.Millisecond / 2)
+	} else if i.sql != nil {
+		sample, err = i.sql.value(variables)
+	} else if i.basicShell != nil {
+		sample, err = i.basicShell.execute()
 	} else if i.ptyShell != nil {
-		return i.ptyShell.execute()
+		sample, err = i.ptyShell.execute()
 	} else {
-		return i.execute(variables, i.sampleScript)
+		sample, err = i.execute(variables)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return i.transform(sample)
+}
+
+func (i *Item) execute(variables []string) (string, error) {
+
+	cmd, input, err := i.newCommand(i.sampleScript, variables, false)
+	if err != nil {
+		return "", err
+	}
+
+	if len(input) > 0 {
+		cmd.Stdin = strings.NewReader(input)
 	}
+
+	return output(cmd)
 }
 
-func (i *Item) execute(variables []string, script string) (string, error) {
+// newCommand creates a command to execute the script locally, or on the remote host.
+// The returned input should be written to the command stdin first, see SshConnection.command
+func (i *Item) newCommand(script string, variables []string, tty bool) (*exec.Cmd, string, error) {
+
+	if i.ssh == nil {
+		cmd := exec.Command("sh", "-c", script)
+		enrichEnvVariables(cmd, variables)
+		return cmd, "", nil
+	}
+
+	return getSshConnection(*i.ssh).command(script, variables, tty)
+}
 
+func execute(variables []string, script string) (string, error) {
 	cmd := exec.Command("sh", "-c", script)
 	enrichEnvVariables(cmd, variables)
+	return output(cmd)
+}
+
+func output(cmd *exec.Cmd) (string, error) {
 
 	output, err := cmd.Output()
 
@@ -80,20 +147,37 @@ func (i *Item) initInteractiveShell(v []string) error {
 
 	timeout := time.Duration(i.rateMs) * time.Millisecond * 3 / 4
 
+	// the shell is used only after the init is complete, and is retried on the next sample otherwise
 	if i.pty {
-		i.ptyShell = &PtyInteractiveShell{item: i, variables: v, timeout: timeout}
-		return i.ptyShell.init()
+		shell := &PtyInteractiveShell{item: i, variables: v, timeout: timeout}
+		if err := shell.init(); err != nil {
+			return err
+		}
+		i.ptyShell = shell
+		return nil
 	}
 
-	i.basicShell = &BasicInteractiveShell{item: i, variables: v, timeout: timeout}
+	shell := &BasicInteractiveShell{item: i, variables: v, timeout: timeout}
+	if err := shell.init(); err != nil {
+		return err
+	}
+	i.basicShell = shell
 
-	return i.basicShell.init()
+	return nil
 }
 
 func (i *Item) transform(sample string) (string, error) {
 
-	if i.transformScript != nil && len(sample) > 0 {
-		return i.execute([]string{"sample=" + sample}, *i.transformScript)
+	var err error
+
+	for _, t := range i.transforms {
+		if len(sample) == 0 {
+			break
+		}
+		sample, err = t.apply(sample)
+		if err != nil {
+			return "", err
+		}
 	}
 
 	return sample, nil
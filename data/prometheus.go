@@ -0,0 +1,271 @@
+package data
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/sqshq/sampler/config"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	prometheusTimeout      = 5 * time.Second
+	prometheusAcceptHeader = "application/openmetrics-text;version=1.0.0,text/plain;version=0.0.4;q=0.9,*/*;q=0.1"
+)
+
+var prometheusClient = &http.Client{Timeout: prometheusTimeout}
+
+var prometheusTargets = struct {
+	sync.Mutex
+	byUrl map[string]*prometheusTarget
+}{byUrl: make(map[string]*prometheusTarget)}
+
+// prometheusTarget is a metrics endpoint, scraped once per tick
+// and shared by all the items, which refer to it
+type prometheusTarget struct {
+	url       string
+	mutex     sync.Mutex
+	scrapedAt time.Time
+	samples   []prometheusSample
+	err       error
+}
+
+type prometheusSample struct {
+	name   string
+	labels map[string]string
+	value  float64
+}
+
+// prometheusQuery selects series by name and label matchers. Values of all the matching
+// series are summed up. Rate is calculated per second between the consecutive scrapes
+type prometheusQuery struct {
+	config.PrometheusQuery
+	target    *prometheusTarget
+	mutex     sync.Mutex
+	prevValue float64
+	prevTime  time.Time
+	lastRate  string
+}
+
+// newPrometheusQuery expects the query to be validated on config load. An invalid query matches nothing
+func newPrometheusQuery(cfg config.PrometheusConfig) *prometheusQuery {
+
+	query, _ := config.ParsePrometheusQuery(cfg.Query)
+
+	return &prometheusQuery{
+		PrometheusQuery: query,
+		target:          getPrometheusTarget(cfg.Url),
+	}
+}
+
+func getPrometheusTarget(url string) *prometheusTarget {
+
+	prometheusTargets.Lock()
+	defer prometheusTargets.Unlock()
+
+	if t, ok := prometheusTargets.byUrl[url]; ok {
+		return t
+	}
+
+	t := &prometheusTarget{url: url}
+	prometheusTargets.byUrl[url] = t
+
+	return t
+}
+
+// value scrapes the target, unless it was scraped less than maxAge ago, and evaluates the query
+func (q *prometheusQuery) value(maxAge time.Duration) (string, error) {
+
+	samples, scrapedAt, err := q.target.scrape(maxAge)
+	if err != nil {
+		return "", err
+	}
+
+	found := false
+	sum := 0.0
+
+	for _, sample := range samples {
+		if q.matches(sample) {
+			found = true
+			sum += sample.value
+		}
+	}
+
+	if !found {
+		return "", fmt.Errorf("no series matches %s on %s", q.selector(), q.target.url)
+	}
+
+	if !q.Rate {
+		return formatMetricValue(sum), nil
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.prevTime.IsZero() || !scrapedAt.After(q.prevTime) {
+		if q.prevTime.IsZero() {
+			q.prevValue, q.prevTime = sum, scrapedAt
+		}
+		return q.lastRate, nil
+	}
+
+	increase := sum - q.prevValue
+	if increase < 0 {
+		// counter is reset, e.g. after the process restart
+		increase = sum
+	}
+
+	q.lastRate = formatMetricValue(increase / scrapedAt.Sub(q.prevTime).Seconds())
+	q.prevValue, q.prevTime = sum, scrapedAt
+
+	return q.lastRate, nil
+}
+
+func (q *prometheusQuery) matches(sample prometheusSample) bool {
+
+	if sample.name != q.Name {
+		return false
+	}
+
+	for _, m := range q.Matchers {
+		value := sample.labels[m.Label]
+		switch m.Operator {
+		case "=":
+			if value != m.Value {
+				return false
+			}
+		case "!=":
+			if value == m.Value {
+				return false
+			}
+		case "=~":
+			if !m.Regex.MatchString(value) {
+				return false
+			}
+		case "!~":
+			if m.Regex.MatchString(value) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (q *prometheusQuery) selector() string {
+
+	var matchers []string
+	for _, m := range q.Matchers {
+		matchers = append(matchers, fmt.Sprintf("%s%s%q", m.Label, m.Operator, m.Value))
+	}
+
+	if len(matchers) == 0 {
+		return q.Name
+	}
+
+	return fmt.Sprintf("%s{%s}", q.Name, strings.Join(matchers, ","))
+}
+
+func (t *prometheusTarget) scrape(maxAge time.Duration) ([]prometheusSample, time.Time, error) {
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if !t.scrapedAt.IsZero() && time.Since(t.scrapedAt) < maxAge {
+		return t.samples, t.scrapedAt, t.err
+	}
+
+	t.scrapedAt = time.Now()
+	t.samples, t.err = t.fetch()
+
+	return t.samples, t.scrapedAt, t.err
+}
+
+func (t *prometheusTarget) fetch() ([]prometheusSample, error) {
+
+	request, err := http.NewRequest(http.MethodGet, t.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", prometheusAcceptHeader)
+
+	response, err := prometheusClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to scrape %s: %s", t.url, response.Status)
+	}
+
+	return parsePrometheusText(response.Body)
+}
+
+// parsePrometheusText parses Prometheus text exposition format, as well as OpenMetrics.
+// Comments, type hints, timestamps and exemplars are ignored
+func parsePrometheusText(reader io.Reader) ([]prometheusSample, error) {
+
+	var samples []prometheusSample
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		sample, err := parsePrometheusLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse metrics line %d: %v", lineNumber, err)
+		}
+
+		samples = append(samples, sample)
+	}
+
+	return samples, scanner.Err()
+}
+
+func parsePrometheusLine(line string) (prometheusSample, error) {
+
+	sample := prometheusSample{labels: make(map[string]string)}
+
+	end := strings.IndexAny(line, "{ \t")
+	if end <= 0 {
+		return sample, fmt.Errorf("value is missing")
+	}
+
+	sample.name = line[:end]
+	rest := line[end:]
+
+	if rest[0] == '{' {
+		labels, remaining, err := config.ParsePrometheusLabels(rest[1:])
+		if err != nil {
+			return sample, err
+		}
+		for _, l := range labels {
+			sample.labels[l[0]] = l[2]
+		}
+		rest = remaining
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return sample, fmt.Errorf("value is missing")
+	}
+
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return sample, fmt.Errorf("invalid value %s", fields[0])
+	}
+	sample.value = value
+
+	return sample, nil
+}
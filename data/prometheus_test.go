@@ -0,0 +1,105 @@
+package data
+
+import (
+	"fmt"
+	"github.com/sqshq/sampler/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const prometheusMetrics = `# HELP http_requests_total Total number of HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{code="200",method="get"} %d
+http_requests_total{code="500",method="get"} 3
+http_requests_total{code="500",method="post"} 4 1612345678000
+# TYPE process_open_fds gauge
+process_open_fds 17
+jvm_info{version="11.0.2+9, \"LTS\""} 1
+queue_size{name="a"} +Inf
+# EOF
+`
+
+func TestParsePrometheusText(t *testing.T) {
+
+	samples, err := parsePrometheusText(strings.NewReader(fmt.Sprintf(prometheusMetrics, 10)))
+	if err != nil {
+		t.Fatalf("parsePrometheusText() error = %v", err)
+	}
+
+	if len(samples) != 6 {
+		t.Fatalf("parsePrometheusText() returned %d samples, want 6", len(samples))
+	}
+	if samples[2].value != 4 || samples[2].labels["method"] != "post" {
+		t.Errorf("parsePrometheusText() sample with timestamp = %+v", samples[2])
+	}
+	if samples[4].labels["version"] != `11.0.2+9, "LTS"` {
+		t.Errorf("parsePrometheusText() escaped label = %q", samples[4].labels["version"])
+	}
+
+	if _, err := parsePrometheusText(strings.NewReader(`broken{code="200" 1`)); err == nil {
+		t.Error("parsePrometheusText() expected an error for unterminated labels")
+	}
+}
+
+func TestPrometheusQuery_value(t *testing.T) {
+
+	var scrapes int32
+	var requests int32 = 10
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&scrapes, 1)
+		_, _ = fmt.Fprintf(w, prometheusMetrics, atomic.LoadInt32(&requests))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`process_open_fds`, "17"},
+		{`http_requests_total`, "17"},
+		{`http_requests_total{code="500"}`, "7"},
+		{`http_requests_total{code="500", method!="post"}`, "3"},
+		{`http_requests_total{code=~"2.."}`, "10"},
+		{`http_requests_total{code!~"5.*"}`, "10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q := newPrometheusQuery(config.PrometheusConfig{Url: server.URL, Query: tt.query})
+			got, err := q.value(time.Hour)
+			if err != nil {
+				t.Fatalf("value() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("value() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if n := atomic.LoadInt32(&scrapes); n != 1 {
+		t.Errorf("target is scraped %d times, want once for all the queries", n)
+	}
+
+	q := newPrometheusQuery(config.PrometheusConfig{Url: server.URL, Query: `http_requests_total{code="404"}`})
+	if _, err := q.value(time.Hour); err == nil {
+		t.Error("value() expected an error for no matching series")
+	}
+
+	rate := newPrometheusQuery(config.PrometheusConfig{Url: server.URL, Query: `rate(http_requests_total{code="200"})`})
+	if got, _ := rate.value(0); got != "" {
+		t.Errorf("rate value() on the first scrape = %q, want empty", got)
+	}
+
+	atomic.StoreInt32(&requests, 30)
+	got, err := rate.value(0)
+	if err != nil {
+		t.Fatalf("rate value() error = %v", err)
+	}
+	if got == "" || got == "0" {
+		t.Errorf("rate value() = %q, want positive rate", got)
+	}
+}
@@ -3,50 +3,82 @@ package data
 import (
 	"fmt"
 	"github.com/sqshq/sampler/config"
+	"sync/atomic"
 	"time"
 )
 
+// triggersBufferSize is the number of samples, which wait for the slow triggers, before the oldest ones are dropped
+const triggersBufferSize = 10
+
 type Sampler struct {
 	consumer        *Consumer
 	items           []*Item
 	triggers        []*Trigger
 	triggersChannel chan *Sample
+	triggersDropped int32
 	variables       []string
-	pause           bool
+	pause           flag
+	hold            flag
+	refresh         chan struct{}
+	stop            chan struct{}
 }
 
-func NewSampler(consumer *Consumer, items []*Item, triggers []*Trigger, options config.Options, fileVariables map[string]string, rateMs int) *Sampler {
-
-	ticker := time.NewTicker(time.Duration(rateMs) * time.Millisecond)
+func NewSampler(consumer *Consumer, items []*Item, triggers []*Trigger, source *config.SourceConfig, options config.Options, fileVariables map[string]string, rateMs int) *Sampler {
 
 	sampler := &Sampler{
 		consumer,
 		items,
 		triggers,
-		make(chan *Sample),
+		make(chan *Sample, triggersBufferSize),
+		0,
 		mergeVariables(fileVariables, options.Environment),
-		false,
+		0,
+		0,
+		make(chan struct{}, 1),
+		make(chan struct{}),
 	}
 
-	go func() {
-		for ; true; <-ticker.C {
-			for _, item := range sampler.items {
-				if !sampler.pause {
-					go sampler.sample(item, options)
+	if source != nil {
+		// samples are pushed by external processes, rate is used for rendering only
+		go sampler.listen(*source)
+	} else {
+		rate := time.Duration(rateMs) * time.Millisecond
+		ticker := time.NewTicker(rate)
+		go func() {
+			defer ticker.Stop()
+			// refresh samples the items even if the sampler is held
+			refresh := false
+			for {
+				for _, item := range sampler.items {
+					// the tick is skipped for an item, which is still being sampled
+					if (refresh || !sampler.pause.get() && !sampler.hold.get()) && item.sampling.acquire() {
+						go sampler.sample(item, options)
+					}
+				}
+				select {
+				case <-ticker.C:
+					refresh = false
+				case <-sampler.refresh:
+					refresh = true
+					ticker.Reset(rate)
+				case <-sampler.stop:
+					return
 				}
 			}
-		}
-	}()
+		}()
+	}
 
 	go func() {
 		for {
 			select {
 			case sample := <-sampler.triggersChannel:
 				for _, t := range sampler.triggers {
-					if !sampler.pause {
+					if !sampler.pause.get() {
 						t.Execute(sample)
 					}
 				}
+			case <-sampler.stop:
+				return
 			}
 		}
 	}()
@@ -54,21 +86,63 @@ func NewSampler(consumer *Consumer, items []*Item, triggers []*Trigger, options
 	return sampler
 }
 
+// sample is called for one item at a time, so the interactive shell of the item is never shared
 func (s *Sampler) sample(item *Item, options config.Options) {
 
+	defer item.sampling.set(false)
+
+	if item.sql != nil && item.sql.bars {
+		s.sampleRows(item)
+		return
+	}
+
+	start := time.Now()
 	val, err := item.nextValue(s.variables)
+	item.recordExecution(time.Since(start), err)
+
+	s.publish(item, val, err)
+}
+
+// sampleRows publishes a sample per row, labeled by the row itself
+func (s *Sampler) sampleRows(item *Item) {
+
+	start := time.Now()
+	rows, err := item.sql.labeledValues(s.variables)
+	item.recordExecution(time.Since(start), err)
+
+	if err != nil {
+		s.publish(item, "", err)
+		return
+	}
+
+	for _, row := range rows {
+		val, err := item.transform(row[1])
+		s.publishAs(item, row[0], val, err)
+	}
+}
+
+func (s *Sampler) publish(item *Item, val string, err error) {
+	s.publishAs(item, item.label, val, err)
+}
 
+func (s *Sampler) publishAs(item *Item, label string, val string, err error) {
+	if s.isStopped() {
+		return
+	}
 	if len(val) > 0 {
-		sample := &Sample{Label: item.label, Value: val, Color: item.color}
-		s.consumer.SampleChannel <- sample
-		s.triggersChannel <- sample
+		sample := &Sample{Label: label, Value: val, Color: item.color}
+		// neither the component nor the triggers block sampling, the oldest samples are dropped instead
+		item.recordSample(s.consumer.PushSample(sample))
+		if dropped := push(s.triggersChannel, sample); dropped > 0 {
+			atomic.AddInt32(&s.triggersDropped, int32(dropped))
+		}
 	} else if err != nil {
-		s.consumer.AlertChannel <- &Alert{
+		s.consumer.PushAlert(&Alert{
 			Title:       "Sampling failure",
 			Text:        getErrorMessage(err),
 			Color:       item.color,
 			Recoverable: true,
-		}
+		})
 	}
 }
 
@@ -85,5 +159,57 @@ func mergeVariables(fileVariables map[string]string, optionsVariables []string)
 }
 
 func (s *Sampler) Pause(pause bool) {
-	s.pause = pause
+	s.pause.set(pause)
+}
+
+// Hold pauses sampling of a single component, which stays paused after the global pause is over
+func (s *Sampler) Hold(hold bool) {
+	s.hold.set(hold)
+}
+
+// Refresh samples all the items right away, without waiting for the next tick.
+// Push sources are not sampled, so refresh has no effect on them
+func (s *Sampler) Refresh() {
+	select {
+	case s.refresh <- struct{}{}:
+	default:
+		// refresh is already requested
+	}
+}
+
+// Stop stops sampling and triggers execution, e.g. when the component is deleted.
+// Push source listener is closed as well, see listen
+func (s *Sampler) Stop() {
+	if !s.isStopped() {
+		close(s.stop)
+	}
+}
+
+func (s *Sampler) isStopped() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// flag is set by the UI goroutine, and read by the sampling ones
+type flag int32
+
+func (f *flag) set(value bool) {
+	var i int32
+	if value {
+		i = 1
+	}
+	atomic.StoreInt32((*int32)(f), i)
+}
+
+func (f *flag) get() bool {
+	return atomic.LoadInt32((*int32)(f)) == 1
+}
+
+// acquire sets the flag, and reports whether it wasn't set before
+func (f *flag) acquire() bool {
+	return atomic.CompareAndSwapInt32((*int32)(f), 0, 1)
 }
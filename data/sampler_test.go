@@ -0,0 +1,155 @@
+package data
+
+import (
+	"github.com/sqshq/sampler/config"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSampler_Stop(t *testing.T) {
+
+	label, script, pty := "value", "echo 1", false
+	items := NewItems([]config.Item{{Label: &label, SampleScript: &script, Pty: &pty}}, 10)
+
+	consumer := NewConsumer()
+	sampler := NewSampler(consumer, items, nil, nil, config.Options{}, nil, 10)
+
+	select {
+	case <-consumer.SampleChannel:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Sampler didn't publish a sample")
+	}
+
+	sampler.Stop()
+	sampler.Stop()
+
+	// samples, which were in flight at the moment of stop, are drained
+	time.Sleep(200 * time.Millisecond)
+	for len(consumer.SampleChannel) > 0 {
+		<-consumer.SampleChannel
+	}
+
+	select {
+	case sample := <-consumer.SampleChannel:
+		t.Errorf("Sampler published %v after stop", sample)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSampler_Hold(t *testing.T) {
+
+	label, script, pty := "value", "echo 1", false
+	items := NewItems([]config.Item{{Label: &label, SampleScript: &script, Pty: &pty}}, 50)
+
+	consumer := NewConsumer()
+	sampler := NewSampler(consumer, items, nil, nil, config.Options{}, nil, 50)
+	defer sampler.Stop()
+
+	sampler.Hold(true)
+	sampler.Pause(false)
+
+	// samples, which were in flight at the moment of hold, are drained
+	time.Sleep(200 * time.Millisecond)
+	for len(consumer.SampleChannel) > 0 {
+		<-consumer.SampleChannel
+	}
+
+	select {
+	case sample := <-consumer.SampleChannel:
+		t.Errorf("Sampler published %v, while held", sample)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	sampler.Hold(false)
+
+	select {
+	case <-consumer.SampleChannel:
+	case <-time.After(2 * time.Second):
+		t.Error("Sampler didn't publish a sample after hold")
+	}
+}
+
+func TestSampler_Refresh(t *testing.T) {
+
+	label, script, pty := "value", "echo 1", false
+	items := NewItems([]config.Item{{Label: &label, SampleScript: &script, Pty: &pty}}, 60000)
+
+	consumer := NewConsumer()
+	sampler := NewSampler(consumer, items, nil, nil, config.Options{}, nil, 60000)
+	defer sampler.Stop()
+
+	select {
+	case <-consumer.SampleChannel:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Sampler didn't publish a sample")
+	}
+
+	// held sampler is refreshed as well, and repeated refresh requests don't block
+	sampler.Hold(true)
+	sampler.Refresh()
+	sampler.Refresh()
+
+	select {
+	case <-consumer.SampleChannel:
+	case <-time.After(2 * time.Second):
+		t.Error("Sampler didn't publish a sample on refresh")
+	}
+}
+
+func TestSampler_concurrentPause(t *testing.T) {
+
+	label, script, pty, off := "value", "echo 1", false, false
+	items := NewItems([]config.Item{{Label: &label, SampleScript: &script, Pty: &pty}}, 10)
+	actions := &config.ActionsConfig{TerminalBell: &off, Sound: &off, Visual: &off}
+	triggers := NewTriggers([]config.TriggerConfig{{Title: "slow", Condition: "sleep 0.1; echo 1", Actions: actions}}, NewConsumer(), config.Options{}, nil)
+
+	// samples are not consumed, and the triggers are slow, but sampling is not blocked by them
+	consumer := NewConsumer()
+	sampler := NewSampler(consumer, items, triggers, nil, config.Options{}, nil, 10)
+	defer sampler.Stop()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				sampler.Pause(j%2 == 0)
+				sampler.Hold((i+j)%3 == 0)
+				sampler.Refresh()
+				sampler.GetStats()
+				time.Sleep(time.Millisecond)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	sampler.Pause(false)
+	sampler.Hold(false)
+
+	deadline := time.After(5 * time.Second)
+	for sampler.GetStats().Items[0].Dropped == 0 {
+		select {
+		case <-deadline:
+			t.Fatalf("GetStats() = %+v, want dropped samples", sampler.GetStats())
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestSampler_slowSample(t *testing.T) {
+
+	label, script, pty := "value", "sleep 0.2; echo 1", false
+	items := NewItems([]config.Item{{Label: &label, SampleScript: &script, Pty: &pty}}, 10)
+
+	// ticks are skipped, while the previous sample of the item is in progress
+	consumer := NewConsumer()
+	sampler := NewSampler(consumer, items, nil, nil, config.Options{}, nil, 10)
+	time.Sleep(500 * time.Millisecond)
+	sampler.Stop()
+
+	if samples := len(consumer.SampleChannel); samples == 0 || samples > 3 {
+		t.Errorf("Sampler published %d samples in 500ms, want one every 200ms", samples)
+	}
+}
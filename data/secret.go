@@ -0,0 +1,117 @@
+package data
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const secretMask = "******"
+
+// secretPattern matches references, resolved by a provider: ${env:NAME}, ${file:/path} or ${cmd:script}
+var secretPattern = regexp.MustCompile(`\$\{(env|file|cmd):([^}]+)}`)
+
+var secrets = struct {
+	sync.RWMutex
+	values []string
+}{}
+
+// ResolveSecrets returns copies of the config and option variables with secret references replaced
+// by their values. Values are kept in memory only, and masked in all the error messages
+func ResolveSecrets(fileVariables map[string]string, optionVariables []string) (map[string]string, []string, error) {
+
+	resolvedFileVariables := make(map[string]string)
+	for name, value := range fileVariables {
+		resolved, err := resolveSecrets(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to resolve variable %s: %v", name, err)
+		}
+		resolvedFileVariables[name] = resolved
+	}
+
+	var resolvedOptionVariables []string
+	for _, variable := range optionVariables {
+		resolved, err := resolveSecrets(variable)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to resolve variable %s: %v", strings.SplitN(variable, "=", 2)[0], err)
+		}
+		resolvedOptionVariables = append(resolvedOptionVariables, resolved)
+	}
+
+	return resolvedFileVariables, resolvedOptionVariables, nil
+}
+
+func resolveSecrets(value string) (string, error) {
+
+	var err error
+
+	resolved := secretPattern.ReplaceAllStringFunc(value, func(reference string) string {
+		match := secretPattern.FindStringSubmatch(reference)
+		secret, e := resolveSecret(match[1], match[2])
+		if e != nil {
+			err = e
+			return ""
+		}
+		registerSecret(secret)
+		return secret
+	})
+
+	return resolved, err
+}
+
+func resolveSecret(provider string, argument string) (string, error) {
+	switch provider {
+	case "env":
+		value, ok := os.LookupEnv(argument)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", argument)
+		}
+		return value, nil
+	case "file":
+		content, err := ioutil.ReadFile(argument)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	default:
+		output, err := exec.Command("sh", "-c", argument).Output()
+		if err != nil {
+			return "", fmt.Errorf("secret command failed: %v", err)
+		}
+		return strings.TrimRight(string(output), "\r\n"), nil
+	}
+}
+
+func registerSecret(secret string) {
+
+	if len(secret) == 0 {
+		return
+	}
+
+	secrets.Lock()
+	defer secrets.Unlock()
+
+	secrets.values = append(secrets.values, secret)
+
+	// longer secrets are masked first, in case one contains another
+	sort.Slice(secrets.values, func(i, j int) bool {
+		return len(secrets.values[i]) > len(secrets.values[j])
+	})
+}
+
+func maskSecrets(text string) string {
+
+	secrets.RLock()
+	defer secrets.RUnlock()
+
+	for _, secret := range secrets.values {
+		text = strings.Replace(text, secret, secretMask, -1)
+	}
+
+	return text
+}
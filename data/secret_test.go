@@ -0,0 +1,67 @@
+package data
+
+import (
+	"errors"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveSecrets(t *testing.T) {
+
+	t.Setenv("SAMPLER_TEST_PASS", "env-secret")
+
+	file := filepath.Join(t.TempDir(), "secret")
+	if err := ioutil.WriteFile(file, []byte("file-secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fileVariables := map[string]string{
+		"dsn":   "postgres://app:${env:SAMPLER_TEST_PASS}@db/app",
+		"token": "${file:" + file + "}",
+		"plain": "value",
+	}
+	optionVariables := []string{"key=${cmd:echo cmd-secret}"}
+
+	variables, environment, err := ResolveSecrets(fileVariables, optionVariables)
+	if err != nil {
+		t.Fatalf("ResolveSecrets() error = %v", err)
+	}
+
+	if variables["dsn"] != "postgres://app:env-secret@db/app" || variables["token"] != "file-secret" || variables["plain"] != "value" {
+		t.Errorf("ResolveSecrets() variables = %v", variables)
+	}
+	if len(environment) != 1 || environment[0] != "key=cmd-secret" {
+		t.Errorf("ResolveSecrets() environment = %v", environment)
+	}
+	if fileVariables["dsn"] != "postgres://app:${env:SAMPLER_TEST_PASS}@db/app" {
+		t.Error("ResolveSecrets() should not modify the config variables")
+	}
+
+	got := getErrorMessage(errors.New("failed to connect to postgres://app:env-secret@db/app with file-secret"))
+	want := "failed to connect to postgres://app:******@db/app with ******"
+	if got != want {
+		t.Errorf("getErrorMessage() = %q, want %q", got, want)
+	}
+
+	if _, _, err := ResolveSecrets(map[string]string{"x": "${env:SAMPLER_TEST_NOT_SET}"}, nil); err == nil {
+		t.Error("ResolveSecrets() expected an error for an unset environment variable")
+	}
+}
+
+func TestGetErrorMessage_truncated(t *testing.T) {
+
+	if _, _, err := ResolveSecrets(map[string]string{"token": "${cmd:echo straddling-secret}"}, nil); err != nil {
+		t.Fatalf("ResolveSecrets() error = %v", err)
+	}
+
+	// stderr is truncated to 200 characters, and the secret starts at 195
+	padding := strings.Repeat("x", 195)
+	got := getErrorMessage(&exec.ExitError{Stderr: []byte(padding + "straddling-secret failure")})
+	want := padding + "*****"
+	if got != want {
+		t.Errorf("getErrorMessage() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,270 @@
+package data
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/sqshq/sampler/config"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	sourceRetryInterval = 1 * time.Second
+	tailInterval        = 100 * time.Millisecond
+)
+
+var errFileRotated = errors.New("file is rotated or truncated")
+
+// listen reads "label value" lines, pushed by external processes into the source,
+// and publishes them as samples of the matching items, until the sampler is stopped
+func (s *Sampler) listen(source config.SourceConfig) {
+
+	fromStart := false
+
+	for {
+		var err error
+
+		switch {
+		case source.Fifo != nil:
+			err = readFifo(*source.Fifo, s.push, s.stop)
+		case source.File != nil:
+			err = tailFile(*source.File, fromStart, s.push, s.stop)
+		case source.Unix != nil:
+			err = listenSocket("unix", *source.Unix, s.push, s.stop)
+		case source.Tcp != nil:
+			err = listenSocket("tcp", *source.Tcp, s.push, s.stop)
+		}
+
+		if s.isStopped() {
+			return
+		}
+
+		// rotated file is read from the start, since all its content is new
+		fromStart = err == errFileRotated
+
+		if err != nil && err != errFileRotated {
+			s.consumer.PushAlert(&Alert{
+				Title:       "Source failure",
+				Text:        getErrorMessage(err),
+				Recoverable: true,
+			})
+			select {
+			case <-time.After(sourceRetryInterval):
+			case <-s.stop:
+				return
+			}
+		}
+	}
+}
+
+func (s *Sampler) push(line string) {
+
+	if s.pause.get() || s.hold.get() || s.isStopped() {
+		return
+	}
+
+	item, value := s.matchItem(strings.TrimSpace(line))
+	if item == nil {
+		return
+	}
+
+	val, err := item.transform(value)
+	s.publish(item, val, err)
+}
+
+// matchItem finds an item with the longest label, which the line starts with.
+// For a single item component the whole line is the value, if there is no label
+func (s *Sampler) matchItem(line string) (*Item, string) {
+
+	var matched *Item
+
+	for _, item := range s.items {
+		if strings.HasPrefix(line, item.label) && (matched == nil || len(item.label) > len(matched.label)) {
+			rest := line[len(item.label):]
+			if len(rest) > 0 && (rest[0] == ' ' || rest[0] == '\t') {
+				matched = item
+			}
+		}
+	}
+
+	if matched != nil {
+		return matched, strings.TrimSpace(line[len(matched.label):])
+	}
+
+	if len(s.items) == 1 && len(line) > 0 {
+		return s.items[0], line
+	}
+
+	return nil, ""
+}
+
+// readFifo creates a named pipe, if it doesn't exist, and reads it until stop. The pipe is opened
+// for writing as well, so opening doesn't wait for a writer, and reading goes on, when writers are closed
+func readFifo(path string, push func(string), stop <-chan struct{}) error {
+
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		if err = makeFifo(path); err != nil {
+			return fmt.Errorf("failed to create fifo %s: %v", path, err)
+		}
+	} else if err != nil {
+		return err
+	} else if info.Mode()&os.ModeNamedPipe == 0 {
+		return fmt.Errorf("%s is not a named pipe. Remove it, or use a file source instead", path)
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	cancel := onStop(stop, func() { _ = file.Close() })
+	defer cancel()
+
+	return scanLines(file, push)
+}
+
+// tailFile reads lines, appended to the file, until it's rotated or truncated, or until stop
+func tailFile(path string, fromStart bool, push func(string), stop <-chan struct{}) error {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var offset int64
+	if !fromStart {
+		if offset, err = file.Seek(0, io.SeekEnd); err != nil {
+			return err
+		}
+	}
+
+	origin, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(file)
+	var partial strings.Builder
+
+	for {
+		chunk, err := reader.ReadString('\n')
+		offset += int64(len(chunk))
+		partial.WriteString(chunk)
+
+		if err == nil {
+			push(partial.String())
+			partial.Reset()
+			continue
+		}
+
+		if err != io.EOF {
+			return err
+		}
+
+		select {
+		case <-time.After(tailInterval):
+		case <-stop:
+			return nil
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if !os.SameFile(origin, info) || info.Size() < offset {
+			return errFileRotated
+		}
+	}
+}
+
+// listenSocket accepts connections and reads lines from each of them. On stop,
+// the listener and all the connections are closed, and unix socket is removed
+func listenSocket(network string, address string, push func(string), stop <-chan struct{}) error {
+
+	// socket, left by a previous run, is removed, but any other file is kept
+	if network == "unix" {
+		info, err := os.Lstat(address)
+		if err == nil && info.Mode()&os.ModeSocket == 0 {
+			return fmt.Errorf("%s is not a unix socket. Remove it, or use another path", address)
+		} else if err == nil {
+			_ = os.Remove(address)
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	var mutex sync.Mutex
+	conns := make(map[net.Conn]bool)
+	closed := false
+
+	cancel := onStop(stop, func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+		closed = true
+		_ = listener.Close()
+		for conn := range conns {
+			_ = conn.Close()
+		}
+	})
+	defer cancel()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+
+		mutex.Lock()
+		if closed {
+			_ = conn.Close()
+		}
+		conns[conn] = true
+		mutex.Unlock()
+
+		go func() {
+			_ = scanLines(conn, push)
+			_ = conn.Close()
+			mutex.Lock()
+			delete(conns, conn)
+			mutex.Unlock()
+		}()
+	}
+}
+
+// onStop calls the function on stop, e.g. to interrupt blocked reading, unless it's canceled before
+func onStop(stop <-chan struct{}, f func()) (cancel func()) {
+
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-stop:
+			f()
+		case <-done:
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+func scanLines(reader io.Reader, push func(string)) error {
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		push(scanner.Text())
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,9 @@
+//+build !windows
+
+package data
+
+import "syscall"
+
+func makeFifo(path string) error {
+	return syscall.Mkfifo(path, 0600)
+}
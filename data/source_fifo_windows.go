@@ -0,0 +1,7 @@
+package data
+
+import "errors"
+
+func makeFifo(path string) error {
+	return errors.New("FIFO source is not supported on Windows")
+}
@@ -0,0 +1,226 @@
+package data
+
+import (
+	"github.com/sqshq/sampler/config"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestSampler_matchItem(t *testing.T) {
+
+	multiple := &Sampler{items: []*Item{{label: "UDP"}, {label: "UDP bytes in"}, {label: "rps"}}}
+	single := &Sampler{items: []*Item{{label: "Local weather"}}}
+
+	tests := []struct {
+		name      string
+		sampler   *Sampler
+		line      string
+		wantLabel string
+		wantValue string
+	}{
+		{"should match label", multiple, "rps 42", "rps", "42"},
+		{"should match the longest label", multiple, "UDP bytes in 1024", "UDP bytes in", "1024"},
+		{"should match label with tab", multiple, "UDP\t7", "UDP", "7"},
+		{"should skip unknown label", multiple, "tcp 1", "", ""},
+		{"should skip label without separator", multiple, "rps42", "", ""},
+		{"should take the whole line for single item", single, "Sunny +21°C", "Local weather", "Sunny +21°C"},
+		{"should strip label for single item", single, "Local weather Rain", "Local weather", "Rain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, value := tt.sampler.matchItem(tt.line)
+			label := ""
+			if item != nil {
+				label = item.label
+			}
+			if label != tt.wantLabel || value != tt.wantValue {
+				t.Errorf("matchItem() = %q, %q, want %q, %q", label, value, tt.wantLabel, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestSampler_pushSources(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "sampler")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fifo := filepath.Join(dir, "fifo")
+	file := filepath.Join(dir, "metrics.log")
+	socket := filepath.Join(dir, "sampler.sock")
+
+	if err = ioutil.WriteFile(file, []byte("rps 1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tcp := getFreeAddress(t)
+
+	tests := []struct {
+		name    string
+		source  config.SourceConfig
+		write   func(lines string) error
+		stopped func() bool
+	}{
+		{"fifo", config.SourceConfig{Fifo: &fifo}, func(lines string) error {
+			return appendFile(fifo, lines, os.O_WRONLY)
+		}, func() bool {
+			// opening without a reader fails, instead of waiting for it
+			return appendFile(fifo, "", os.O_WRONLY|syscall.O_NONBLOCK) != nil
+		}},
+		{"file", config.SourceConfig{File: &file}, func(lines string) error {
+			return appendFile(file, lines, os.O_WRONLY|os.O_APPEND)
+		}, nil},
+		{"unix socket", config.SourceConfig{Unix: &socket}, func(lines string) error {
+			return writeSocket("unix", socket, lines)
+		}, func() bool {
+			_, err := os.Stat(socket)
+			return os.IsNotExist(err)
+		}},
+		{"tcp socket", config.SourceConfig{Tcp: &tcp}, func(lines string) error {
+			return writeSocket("tcp", tcp, lines)
+		}, func() bool {
+			return writeSocket("tcp", tcp, "rps 1\n") != nil
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			consumer := NewConsumer()
+			items := []*Item{{label: "rps"}, {label: "latency"}}
+			sampler := NewSampler(consumer, items, nil, &tt.source, config.Options{}, nil, 1000)
+
+			time.Sleep(3 * tailInterval)
+
+			if err := tt.write("rps 42\nlatency 0.5\nunknown 1\n"); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, want := range []Sample{{Label: "rps", Value: "42"}, {Label: "latency", Value: "0.5"}} {
+				select {
+				case got := <-consumer.SampleChannel:
+					if got.Label != want.Label || got.Value != want.Value {
+						t.Errorf("unexpected sample %v, want %v", *got, want)
+					}
+				case alert := <-consumer.AlertChannel:
+					t.Fatalf("unexpected alert %v", *alert)
+				case <-time.After(2 * time.Second):
+					t.Fatalf("sample %v wasn't received", want)
+				}
+			}
+
+			sampler.Stop()
+			if tt.stopped == nil {
+				return
+			}
+
+			deadline := time.After(2 * time.Second)
+			for !tt.stopped() {
+				select {
+				case <-deadline:
+					t.Fatal("source wasn't closed on stop")
+				case <-time.After(10 * time.Millisecond):
+				}
+			}
+		})
+	}
+}
+
+func TestSampler_fifoRegularFile(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "sampler")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "metrics.log")
+	if err = ioutil.WriteFile(file, []byte("rps 1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	consumer := NewConsumer()
+	sampler := NewSampler(consumer, []*Item{{label: "rps"}}, nil, &config.SourceConfig{Fifo: &file}, config.Options{}, nil, 1000)
+	defer sampler.Stop()
+
+	select {
+	case alert := <-consumer.AlertChannel:
+		if !strings.Contains(alert.Text, "is not a named pipe") {
+			t.Errorf("unexpected alert %v", *alert)
+		}
+	case sample := <-consumer.SampleChannel:
+		t.Fatalf("unexpected sample %v", *sample)
+	case <-time.After(2 * time.Second):
+		t.Fatal("regular file wasn't reported")
+	}
+}
+
+func TestSampler_unixRegularFile(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "sampler")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "metrics.sock")
+	if err = ioutil.WriteFile(file, []byte("rps 1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	consumer := NewConsumer()
+	sampler := NewSampler(consumer, []*Item{{label: "rps"}}, nil, &config.SourceConfig{Unix: &file}, config.Options{}, nil, 1000)
+	defer sampler.Stop()
+
+	select {
+	case alert := <-consumer.AlertChannel:
+		if !strings.Contains(alert.Text, "is not a unix socket") {
+			t.Errorf("unexpected alert %v", *alert)
+		}
+	case sample := <-consumer.SampleChannel:
+		t.Fatalf("unexpected sample %v", *sample)
+	case <-time.After(2 * time.Second):
+		t.Fatal("regular file wasn't reported")
+	}
+
+	if content, err := ioutil.ReadFile(file); err != nil || string(content) != "rps 1\n" {
+		t.Errorf("regular file was changed: %q, %v", content, err)
+	}
+}
+
+func getFreeAddress(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+func writeSocket(network string, address string, lines string) error {
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte(lines))
+	return err
+}
+
+func appendFile(path string, lines string, flag int) error {
+	file, err := os.OpenFile(path, flag, 0)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.WriteString(lines)
+	return err
+}
@@ -0,0 +1,221 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/sqshq/sampler/config"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+const (
+	sqlQueryTimeout    = 10 * time.Second
+	sqlMaxOpenConns    = 2
+	sqlConnMaxLifetime = 5 * time.Minute
+	sqlTimeLayout      = "2006-01-02 15:04:05"
+)
+
+// sqlBuildTags lists the drivers, which are included into the binary with a build tag only
+var sqlBuildTags = map[string]string{
+	string(config.SqlDriverSqlite): "sqlite",
+}
+
+var sqlPools = struct {
+	sync.Mutex
+	byDsn map[string]*sql.DB
+}{byDsn: make(map[string]*sql.DB)}
+
+// sqlQuery is executed on a connection pool, which is shared
+// by all the items with the same driver and data source
+type sqlQuery struct {
+	driver string
+	dsn    string
+	query  string
+	bars   bool
+}
+
+func newSqlQuery(cfg config.SqlConfig) *sqlQuery {
+	return &sqlQuery{
+		driver: string(cfg.Driver),
+		dsn:    cfg.Dsn,
+		query:  cfg.Query,
+		bars:   cfg.Rows != nil && *cfg.Rows == config.SqlRowsBars,
+	}
+}
+
+// CloseSqlConnections closes all the connection pools
+func CloseSqlConnections() {
+
+	sqlPools.Lock()
+	defer sqlPools.Unlock()
+
+	for key, db := range sqlPools.byDsn {
+		_ = db.Close()
+		delete(sqlPools.byDsn, key)
+	}
+}
+
+func getSqlPool(driver string, dsn string) (*sql.DB, error) {
+
+	sqlPools.Lock()
+	defer sqlPools.Unlock()
+
+	key := driver + "\x00" + dsn
+	if db, ok := sqlPools.byDsn[key]; ok {
+		return db, nil
+	}
+
+	if !isSqlDriverRegistered(driver) {
+		return nil, fmt.Errorf("%s driver is not included into this build. Build sampler with -tags %s", driver, sqlBuildTags[driver])
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(sqlMaxOpenConns)
+	db.SetMaxIdleConns(sqlMaxOpenConns)
+	db.SetConnMaxLifetime(sqlConnMaxLifetime)
+
+	sqlPools.byDsn[key] = db
+
+	return db, nil
+}
+
+func isSqlDriverRegistered(driver string) bool {
+	for _, d := range sql.Drivers() {
+		if d == driver {
+			return true
+		}
+	}
+	return false
+}
+
+// value returns a single value as is, and any other row set as a text table with a header
+func (q *sqlQuery) value(variables []string) (string, error) {
+
+	columns, rows, err := q.execute(variables)
+	if err != nil {
+		return "", err
+	}
+
+	if len(columns) == 1 && len(rows) <= 1 {
+		if len(rows) == 0 {
+			return "", nil
+		}
+		return rows[0][0], nil
+	}
+
+	var builder strings.Builder
+	writer := tabwriter.NewWriter(&builder, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(writer, strings.Join(columns, "\t"))
+	for _, row := range rows {
+		_, _ = fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	_ = writer.Flush()
+
+	return builder.String(), nil
+}
+
+// labeledValues returns a label from the first column and a value from the last column of each row
+func (q *sqlQuery) labeledValues(variables []string) ([][2]string, error) {
+
+	columns, rows, err := q.execute(variables)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(columns) < 2 {
+		return nil, fmt.Errorf("sql query should return label and value columns, got %d column(s)", len(columns))
+	}
+
+	var values [][2]string
+	for _, row := range rows {
+		values = append(values, [2]string{row[0], row[len(row)-1]})
+	}
+
+	return values, nil
+}
+
+func (q *sqlQuery) execute(variables []string) ([]string, [][]string, error) {
+
+	db, err := getSqlPool(q.driver, expandVariables(q.dsn, variables))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sqlQueryTimeout)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, q.query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var result [][]string
+
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err = rows.Scan(pointers...); err != nil {
+			return nil, nil, err
+		}
+		row := make([]string, len(columns))
+		for i, v := range values {
+			row[i] = formatSqlValue(v)
+		}
+		result = append(result, row)
+	}
+
+	return columns, result, rows.Err()
+}
+
+func formatSqlValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return string(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format(sqlTimeLayout)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// expandVariables replaces $NAME and ${NAME} with config or option
+// variables, falling back to the environment variables
+func expandVariables(text string, variables []string) string {
+	return os.Expand(text, func(name string) string {
+		value, found := "", false
+		for _, variable := range variables {
+			if strings.HasPrefix(variable, name+"=") {
+				value, found = variable[len(name)+1:], true
+			}
+		}
+		if !found {
+			value = os.Getenv(name)
+		}
+		return value
+	})
+}
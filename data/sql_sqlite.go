@@ -0,0 +1,8 @@
+//go:build sqlite
+// +build sqlite
+
+package data
+
+// SQLite driver requires cgo, so it's included with "sqlite" build tag only,
+// e.g. go build -tags sqlite. Default builds are kept cgo-free for cross compilation
+import _ "github.com/mattn/go-sqlite3"
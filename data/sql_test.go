@@ -0,0 +1,85 @@
+package data
+
+import (
+	"github.com/sqshq/sampler/config"
+	"testing"
+
+	// the driver is included into the binary with "sqlite" build tag only, see sql_sqlite.go
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSqlQuery(t *testing.T) {
+
+	dir := t.TempDir()
+	defer CloseSqlConnections()
+
+	dsn := "$SAMPLER_TEST_DIR/test.db"
+	variables := []string{"SAMPLER_TEST_DIR=" + dir}
+
+	db, err := getSqlPool(string(config.SqlDriverSqlite), expandVariables(dsn, variables))
+	if err != nil {
+		t.Fatalf("getSqlPool() error = %v", err)
+	}
+	_, err = db.Exec(`
+		CREATE TABLE queues (name TEXT, size INTEGER);
+		INSERT INTO queues VALUES ('emails', 12), ('reports', 3), ('exports', NULL)`)
+	if err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"should return scalar", "SELECT SUM(size) FROM queues", "15"},
+		{"should return nothing for no rows", "SELECT size FROM queues WHERE name = 'none'", ""},
+		{"should return table", "SELECT name, size FROM queues ORDER BY name",
+			"name     size\nemails   12\nexports  NULL\nreports  3\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newSqlQuery(config.SqlConfig{Driver: config.SqlDriverSqlite, Dsn: dsn, Query: tt.query})
+			got, err := q.value(variables)
+			if err != nil {
+				t.Fatalf("value() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("value() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	bars := config.SqlRowsBars
+	q := newSqlQuery(config.SqlConfig{Driver: config.SqlDriverSqlite, Dsn: dsn, Query: "SELECT name, size FROM queues WHERE size > 0 ORDER BY size", Rows: &bars})
+	got, err := q.labeledValues(variables)
+	if err != nil {
+		t.Fatalf("labeledValues() error = %v", err)
+	}
+	if len(got) != 2 || got[0] != [2]string{"reports", "3"} || got[1] != [2]string{"emails", "12"} {
+		t.Errorf("labeledValues() = %v", got)
+	}
+
+	if _, err := newSqlQuery(config.SqlConfig{Driver: config.SqlDriverSqlite, Dsn: dsn, Query: "SELECT nope FROM queues"}).value(variables); err == nil {
+		t.Error("value() expected an error for an invalid query")
+	}
+
+	sqlPools.Lock()
+	pools := len(sqlPools.byDsn)
+	sqlPools.Unlock()
+	if pools != 1 {
+		t.Errorf("%d connection pools are opened, want a single shared pool", pools)
+	}
+}
+
+func TestExpandVariables(t *testing.T) {
+	t.Setenv("SAMPLER_TEST_HOST", "env-host")
+	got := expandVariables("postgres://${USER_NAME}@$SAMPLER_TEST_HOST/db", []string{"USER_NAME=file", "USER_NAME=option"})
+	want := "postgres://option@env-host/db"
+	if got != want {
+		t.Errorf("expandVariables() = %q, want %q", got, want)
+	}
+	if got := expandVariables("$SAMPLER_NOT_SET/x", nil); got != "/x" {
+		t.Error("expandVariables() expected an empty value for an unknown variable")
+	}
+}
@@ -0,0 +1,332 @@
+package data
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	sshConnectTimeout = 10 * time.Second
+	sshCheckInterval  = 100 * time.Millisecond
+	sshMinBackoff     = 1 * time.Second
+	sshMaxBackoff     = 30 * time.Second
+	sshAliveInterval  = "10"
+	sshAliveCountMax  = "3"
+	sshControlDirName = "sampler-ssh"
+	// sshReadyMarker is printed by the remote PTY session, when the echo is disabled to read the variables
+	sshReadyMarker = "SAMPLER_SSH_READY"
+)
+
+// sshBinary is an OpenSSH client executable. Host aliases, keys
+// and known_hosts are taken from the user's ~/.ssh/config by the client
+var sshBinary = "ssh"
+
+var sshConnections = struct {
+	sync.Mutex
+	byHost map[string]*SshConnection
+}{byHost: make(map[string]*SshConnection)}
+
+// SshConnection represents a persistent connection to a remote host,
+// which is shared by all the sampling scripts using OpenSSH multiplexing
+type SshConnection struct {
+	binary      string
+	host        string
+	controlPath string
+	mutex       sync.Mutex
+	connected   bool
+	connecting  bool
+	err         error
+	retryAt     time.Time
+	changed     chan struct{}
+	master      *exec.Cmd
+	closed      bool
+}
+
+func getSshConnection(host string) *SshConnection {
+
+	sshConnections.Lock()
+	defer sshConnections.Unlock()
+
+	if c, ok := sshConnections.byHost[host]; ok {
+		return c
+	}
+
+	c := &SshConnection{
+		binary:      sshBinary,
+		host:        host,
+		controlPath: getControlPath(host),
+		changed:     make(chan struct{}),
+		connecting:  true,
+	}
+
+	sshConnections.byHost[host] = c
+	go c.maintain()
+
+	return c
+}
+
+// CloseSshConnections terminates all the master connections
+func CloseSshConnections() {
+
+	sshConnections.Lock()
+	defer sshConnections.Unlock()
+
+	for host, c := range sshConnections.byHost {
+		c.close()
+		delete(sshConnections.byHost, host)
+		_ = os.RemoveAll(filepath.Dir(c.controlPath))
+	}
+}
+
+// command creates a command to execute the script on the remote host through the master connection.
+// Variables are not passed in the command line, which is visible to other users in the process list.
+// Instead, the returned input is expected to be written to the command stdin before anything else
+func (c *SshConnection) command(script string, variables []string, tty bool) (*exec.Cmd, string, error) {
+
+	if err := c.await(); err != nil {
+		return nil, "", err
+	}
+
+	ttyFlag := "-T"
+	if tty {
+		ttyFlag = "-tt"
+	}
+
+	input := exportVariables(variables)
+
+	return exec.Command(c.binary,
+		"-S", c.controlPath,
+		"-o", "ControlMaster=no",
+		"-o", "BatchMode=yes",
+		ttyFlag,
+		c.host,
+		importVariables(input, tty)+script,
+	), input, nil
+}
+
+// await blocks until the connection is established, or returns an error
+// immediately if it's lost and a reconnection is scheduled
+func (c *SshConnection) await() error {
+
+	deadline := time.After(sshConnectTimeout)
+
+	for {
+		c.mutex.Lock()
+		connected, connecting, err, changed, retryAt := c.connected, c.connecting, c.err, c.changed, c.retryAt
+		c.mutex.Unlock()
+
+		if connected {
+			return nil
+		}
+
+		if !connecting {
+			return fmt.Errorf("SSH connection to %s is lost: %v. Reconnecting in %v",
+				c.host, err, time.Until(retryAt).Round(time.Second))
+		}
+
+		select {
+		case <-changed:
+		case <-deadline:
+			return fmt.Errorf("SSH connection to %s is not established in %v", c.host, sshConnectTimeout)
+		}
+	}
+}
+
+// maintain keeps the master connection open, reconnecting with exponential backoff
+func (c *SshConnection) maintain() {
+
+	backoff := sshMinBackoff
+
+	for !c.isClosed() {
+		c.setState(false, true, nil, time.Time{})
+
+		master := exec.Command(c.binary,
+			"-M", "-N",
+			"-S", c.controlPath,
+			"-o", "ControlPersist=no",
+			"-o", "BatchMode=yes",
+			"-o", "ServerAliveInterval="+sshAliveInterval,
+			"-o", "ServerAliveCountMax="+sshAliveCountMax,
+			c.host,
+		)
+
+		var stderr bytes.Buffer
+		var exitErr error
+		master.Stderr = &stderr
+		exited := make(chan struct{})
+
+		err := os.MkdirAll(filepath.Dir(c.controlPath), 0700)
+		if err == nil {
+			err = c.startMaster(master)
+			if err == nil {
+				go func() {
+					exitErr = master.Wait()
+					close(exited)
+				}()
+				err = c.awaitMaster(exited)
+				if err == nil {
+					backoff = sshMinBackoff
+					c.setState(true, false, nil, time.Time{})
+				}
+				<-exited
+				if err == nil {
+					err = exitErr
+				}
+			}
+		}
+
+		if c.isClosed() {
+			return
+		}
+
+		if message := strings.TrimSpace(stderr.String()); len(message) > 0 {
+			err = fmt.Errorf("%.200s", message)
+		} else if err == nil {
+			err = fmt.Errorf("master connection closed")
+		}
+
+		c.setState(false, false, err, time.Now().Add(backoff))
+		time.Sleep(backoff)
+
+		backoff *= 2
+		if backoff > sshMaxBackoff {
+			backoff = sshMaxBackoff
+		}
+	}
+}
+
+func (c *SshConnection) startMaster(master *exec.Cmd) error {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed {
+		return fmt.Errorf("SSH connection to %s is closed", c.host)
+	}
+
+	c.master = master
+
+	return master.Start()
+}
+
+// awaitMaster waits until the master connection accepts multiplexed sessions
+func (c *SshConnection) awaitMaster(exited chan struct{}) error {
+
+	deadline := time.After(sshConnectTimeout)
+
+	for {
+		check := exec.Command(c.binary, "-S", c.controlPath, "-O", "check", c.host)
+		if check.Run() == nil {
+			return nil
+		}
+
+		select {
+		case <-exited:
+			return fmt.Errorf("master connection closed")
+		case <-deadline:
+			_ = c.master.Process.Kill()
+			return fmt.Errorf("connection timeout")
+		case <-time.After(sshCheckInterval):
+		}
+	}
+}
+
+func (c *SshConnection) setState(connected bool, connecting bool, err error, retryAt time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.connected = connected
+	c.connecting = connecting
+	c.err = err
+	c.retryAt = retryAt
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+func (c *SshConnection) isClosed() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.closed
+}
+
+func (c *SshConnection) close() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.closed = true
+	if c.master != nil && c.master.Process != nil {
+		_ = c.master.Process.Kill()
+	}
+	_ = os.Remove(c.controlPath)
+}
+
+// getControlPath returns a short path, since UNIX socket path length is limited
+func getControlPath(host string) string {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(host))
+	dir := filepath.Join(os.TempDir(), fmt.Sprintf("%s-%d", sshControlDirName, os.Getpid()))
+	return filepath.Join(dir, fmt.Sprintf("%x", hash.Sum32()))
+}
+
+// exportVariables returns the export statements, one per line, unless the value contains line breaks
+func exportVariables(variables []string) string {
+
+	var builder strings.Builder
+
+	for _, variable := range variables {
+		parts := strings.SplitN(variable, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("export %s=%s\n", parts[0], quote(parts[1])))
+	}
+
+	return builder.String()
+}
+
+// importVariables returns a script, which reads the export statements from stdin and executes them.
+// Input is read line by line, since the shell read builtin doesn't consume the rest of the stdin,
+// which belongs to the interactive shell. In PTY mode, the terminal is switched to raw mode
+// without echo while reading, and the ready marker tells when the input can be written
+func importVariables(input string, tty bool) string {
+
+	if len(input) == 0 {
+		return ""
+	}
+
+	script := fmt.Sprintf("__n=%d; __v=; while [ $__n -gt 0 ] && IFS= read -r __l; do __v=\"$__v$__l\n\"; __n=$((__n-1)); done; "+
+		"eval \"$__v\"; unset __n __v __l; ", strings.Count(input, "\n"))
+
+	if tty {
+		script = fmt.Sprintf("__s=$(stty -g); stty raw -echo; echo %s; %sstty \"$__s\"; unset __s; ", sshReadyMarker, script)
+	}
+
+	return script
+}
+
+// awaitSshReady reads the PTY session output until the ready marker, see importVariables
+func awaitSshReady(reader io.Reader) error {
+
+	var output string
+	buffer := make([]byte, 4096)
+
+	for !strings.Contains(output, sshReadyMarker) {
+		n, err := reader.Read(buffer)
+		if err != nil {
+			return fmt.Errorf("SSH session is closed before the variables are exported: %v", err)
+		}
+		output += string(buffer[:n])
+	}
+
+	return nil
+}
+
+func quote(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
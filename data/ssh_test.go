@@ -0,0 +1,181 @@
+package data
+
+import (
+	"github.com/sqshq/sampler/config"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSsh emulates OpenSSH client with connection multiplexing, executing
+// remote commands locally. Removal of the control path emulates connection loss
+const fakeSsh = `#!/bin/sh
+ctl=""; master=""; check=""
+while [ $# -gt 0 ]; do
+  case "$1" in
+    -S) ctl="$2"; shift 2 ;;
+    -o) shift 2 ;;
+    -O) check=1; shift 2 ;;
+    -M) master=1; shift ;;
+    -*) shift ;;
+    *) break ;;
+  esac
+done
+host="$1"; shift
+if [ -n "$master" ]; then
+  if [ "$host" = "unreachable" ]; then
+    echo "ssh: connect to host unreachable port 22: Connection refused" >&2; exit 255
+  fi
+  touch "$ctl"
+  while [ -e "$ctl" ]; do sleep 0.05; done
+  echo "Connection to $host closed by remote host." >&2; exit 255
+fi
+if [ -n "$check" ]; then
+  [ -e "$ctl" ]; exit $?
+fi
+if [ ! -e "$ctl" ]; then
+  echo "Control socket connect($ctl): No such file or directory" >&2; exit 255
+fi
+exec sh -c "$1"
+`
+
+func setupFakeSsh(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "sampler")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	binary := filepath.Join(dir, "ssh")
+	if err = ioutil.WriteFile(binary, []byte(fakeSsh), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	sshBinary = binary
+	t.Cleanup(func() {
+		CloseSshConnections()
+		sshBinary = "ssh"
+		_ = os.RemoveAll(dir)
+	})
+}
+
+func TestSshConnection_sample(t *testing.T) {
+
+	setupFakeSsh(t)
+
+	host := "remote"
+	item := &Item{label: "remote", sampleScript: "echo $greeting", ssh: &host}
+
+	for i := 0; i < 3; i++ {
+		got, err := item.nextValue([]string{"greeting=it's alive"})
+		if err != nil {
+			t.Fatalf("nextValue() error = %v", err)
+		}
+		if got != "it's alive\n" {
+			t.Errorf("nextValue() = %q, want %q", got, "it's alive\n")
+		}
+	}
+
+	if len(sshConnections.byHost) != 1 {
+		t.Errorf("expected a single connection per host, got %d", len(sshConnections.byHost))
+	}
+}
+
+func TestSshConnection_hiddenVariables(t *testing.T) {
+
+	setupFakeSsh(t)
+
+	host := "remote"
+	variables := []string{"secret=s3cr3t", "multiline=a\nb"}
+	connection := getSshConnection(host)
+
+	for _, tty := range []bool{false, true} {
+		cmd, input, err := connection.command("echo $secret", variables, tty)
+		if err != nil {
+			t.Fatalf("command() error = %v", err)
+		}
+		if args := strings.Join(cmd.Args, " "); strings.Contains(args, "s3cr3t") {
+			t.Errorf("command() args = %q, want no variable values", args)
+		}
+		if want := "export secret='s3cr3t'\nexport multiline='a\nb'\n"; input != want {
+			t.Errorf("command() input = %q, want %q", input, want)
+		}
+	}
+}
+
+func TestSshConnection_interactive(t *testing.T) {
+
+	setupFakeSsh(t)
+
+	host, marker, timeout := "remote", "echo $marker", 2000
+	item := &Item{
+		initScripts:  []string{"sh"},
+		sampleScript: "echo \"$greeting\" $(echo \"$multiline\" | wc -l)",
+		rateMs:       1000,
+		ssh:          &host,
+		completion:   &config.CompletionConfig{Marker: &marker, TimeoutMs: &timeout},
+	}
+
+	// variables are read from stdin, and the rest of it is left to the interactive shell
+	shell := &BasicInteractiveShell{item: item, variables: []string{"greeting=it's alive", "multiline=a\nb"}}
+	if err := shell.init(); err != nil {
+		t.Fatalf("init() error = %v", err)
+	}
+	defer func() { _ = shell.cmd.Process.Kill() }()
+
+	got, err := shell.execute()
+	if err != nil {
+		t.Fatalf("execute() error = %v", err)
+	}
+	if want := "it's alive 2\n"; got != want {
+		t.Errorf("execute() = %q, want %q", got, want)
+	}
+}
+
+func TestSshConnection_reconnect(t *testing.T) {
+
+	setupFakeSsh(t)
+
+	host := "flaky"
+	item := &Item{label: "flaky", sampleScript: "echo 1", ssh: &host}
+
+	if _, err := item.nextValue(nil); err != nil {
+		t.Fatalf("nextValue() error = %v", err)
+	}
+
+	// emulate connection loss
+	connection := getSshConnection(host)
+	_ = os.Remove(connection.controlPath)
+	time.Sleep(500 * time.Millisecond)
+
+	_, err := item.nextValue(nil)
+	if err == nil || !strings.Contains(err.Error(), "closed by remote host") {
+		t.Fatalf("nextValue() error = %v, want connection loss", err)
+	}
+
+	time.Sleep(sshMinBackoff + 500*time.Millisecond)
+
+	got, err := item.nextValue(nil)
+	if err != nil {
+		t.Fatalf("nextValue() after reconnect error = %v", err)
+	}
+	if got != "1\n" {
+		t.Errorf("nextValue() after reconnect = %q, want %q", got, "1\n")
+	}
+}
+
+func TestSshConnection_unreachable(t *testing.T) {
+
+	setupFakeSsh(t)
+
+	host := "unreachable"
+	item := &Item{label: "unreachable", sampleScript: "echo 1", ssh: &host}
+
+	_, err := item.nextValue(nil)
+	if err == nil || !strings.Contains(err.Error(), "Connection refused") {
+		t.Fatalf("nextValue() error = %v, want connection failure", err)
+	}
+}
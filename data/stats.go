@@ -0,0 +1,89 @@
+package data
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ItemStats describes how the item is sampled, so the scripts, which slow down the dashboard, can be found
+type ItemStats struct {
+	Label        string
+	Samples      int
+	Executions   int
+	Failures     int
+	Timeouts     int // executions, which took longer than the sampling rate
+	Dropped      int // queued samples, which were replaced by the samples of the item, since the component was behind
+	LastDuration time.Duration
+	AvgDuration  time.Duration
+}
+
+// SamplerStats describes the sampler items, and the samples, queued for the component
+type SamplerStats struct {
+	Items           []ItemStats
+	Queue           int
+	QueueCap        int
+	TriggersDropped int // queued samples of all the items, which were replaced, since the triggers were behind
+}
+
+// itemStats is updated by the concurrent sampling goroutines
+type itemStats struct {
+	sync.Mutex
+	ItemStats
+	total time.Duration
+}
+
+// recordExecution counts the script execution or the query, which took the duration
+func (i *Item) recordExecution(duration time.Duration, err error) {
+
+	i.stats.Lock()
+	defer i.stats.Unlock()
+
+	i.stats.Executions++
+	if err != nil {
+		i.stats.Failures++
+	}
+	if i.rateMs > 0 && duration > time.Duration(i.rateMs)*time.Millisecond {
+		i.stats.Timeouts++
+	}
+
+	i.stats.LastDuration = duration
+	i.stats.total += duration
+	i.stats.AvgDuration = i.stats.total / time.Duration(i.stats.Executions)
+}
+
+// recordSample counts the published sample, including the pushed ones, and the queued samples it replaced
+func (i *Item) recordSample(dropped int) {
+
+	i.stats.Lock()
+	defer i.stats.Unlock()
+
+	i.stats.Samples++
+	i.stats.Dropped += dropped
+}
+
+func (i *Item) GetStats() ItemStats {
+
+	i.stats.Lock()
+	defer i.stats.Unlock()
+
+	stats := i.stats.ItemStats
+	stats.Label = i.label
+
+	return stats
+}
+
+func (s *Sampler) GetStats() SamplerStats {
+
+	stats := SamplerStats{
+		Queue:           len(s.consumer.SampleChannel),
+		QueueCap:        cap(s.consumer.SampleChannel),
+		TriggersDropped: int(atomic.LoadInt32(&s.triggersDropped)),
+	}
+
+	for _, item := range s.items {
+		stats.Items = append(stats.Items, item.GetStats())
+	}
+
+	return stats
+}
@@ -0,0 +1,81 @@
+package data
+
+import (
+	"errors"
+	"github.com/sqshq/sampler/config"
+	"testing"
+	"time"
+)
+
+func TestItem_recordExecution(t *testing.T) {
+
+	item := &Item{label: "value", rateMs: 100}
+
+	item.recordExecution(50*time.Millisecond, nil)
+	item.recordExecution(150*time.Millisecond, nil)
+	item.recordExecution(10*time.Millisecond, errors.New("exit status 1"))
+	item.recordSample(0)
+	item.recordSample(1)
+
+	want := ItemStats{
+		Label:        "value",
+		Samples:      2,
+		Executions:   3,
+		Failures:     1,
+		Timeouts:     1,
+		Dropped:      1,
+		LastDuration: 10 * time.Millisecond,
+		AvgDuration:  70 * time.Millisecond,
+	}
+
+	if got := item.GetStats(); got != want {
+		t.Errorf("GetStats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSampler_GetStats(t *testing.T) {
+
+	label, script, pty := "value", "echo 1", false
+	items := NewItems([]config.Item{{Label: &label, SampleScript: &script, Pty: &pty}}, 10)
+
+	// samples are not consumed, so the channel buffer is filled up, and the oldest samples are dropped
+	consumer := NewConsumer()
+	sampler := NewSampler(consumer, items, nil, nil, config.Options{}, nil, 10)
+	defer sampler.Stop()
+
+	deadline := time.After(5 * time.Second)
+	for {
+		stats := sampler.GetStats()
+		if stats.Queue == stats.QueueCap && stats.Items[0].Dropped > 0 {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("GetStats() = %+v, want full queue and dropped samples", stats)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	sampler.Stop()
+	for len(consumer.SampleChannel) > 0 {
+		<-consumer.SampleChannel
+	}
+}
+
+func TestSampler_GetStats_triggersDropped(t *testing.T) {
+
+	item := &Item{label: "value"}
+	consumer := NewConsumer()
+	sampler := &Sampler{consumer: consumer, items: []*Item{item}, triggersChannel: make(chan *Sample, triggersBufferSize)}
+
+	// the component keeps up, while the triggers don't receive the samples at all
+	for i := 0; i < triggersBufferSize+5; i++ {
+		sampler.publish(item, "1", nil)
+		<-consumer.SampleChannel
+	}
+
+	stats := sampler.GetStats()
+	if stats.TriggersDropped != 5 || stats.Items[0].Dropped != 0 {
+		t.Errorf("GetStats() = %+v, want 5 samples dropped by the triggers only", stats)
+	}
+}
@@ -0,0 +1,285 @@
+package data
+
+import (
+	"fmt"
+	"github.com/sqshq/sampler/config"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const statsdPacketSize = 65535
+
+var statsd = struct {
+	sync.Mutex
+	listener *StatsdListener
+}{}
+
+// StatsdListener receives metrics in StatsD format over UDP and aggregates them in memory.
+// Counters, timers and sets are reported for the last completed flush interval, gauges are reported as is
+type StatsdListener struct {
+	conn     net.PacketConn
+	interval time.Duration
+	mutex    sync.Mutex
+	counters map[string]float64
+	timers   map[string][]float64
+	sets     map[string]map[string]bool
+	gauges   map[string]float64
+	flushed  map[string]float64
+	closed   chan struct{}
+}
+
+// StartStatsd starts the listener, which is used by all the items with a statsd metric
+func StartStatsd(cfg config.StatsdConfig) error {
+
+	listener, err := NewStatsdListener(cfg.Addr, time.Duration(*cfg.FlushMs)*time.Millisecond)
+	if err != nil {
+		return fmt.Errorf("failed to start statsd listener on %s: %v", cfg.Addr, err)
+	}
+
+	statsd.Lock()
+	statsd.listener = listener
+	statsd.Unlock()
+
+	return nil
+}
+
+// CloseStatsd stops the listener, if it's started
+func CloseStatsd() {
+
+	statsd.Lock()
+	defer statsd.Unlock()
+
+	if statsd.listener != nil {
+		statsd.listener.close()
+		statsd.listener = nil
+	}
+}
+
+func NewStatsdListener(addr string, interval time.Duration) (*StatsdListener, error) {
+
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &StatsdListener{
+		conn:     conn,
+		interval: interval,
+		counters: make(map[string]float64),
+		timers:   make(map[string][]float64),
+		sets:     make(map[string]map[string]bool),
+		gauges:   make(map[string]float64),
+		flushed:  make(map[string]float64),
+		closed:   make(chan struct{}),
+	}
+
+	go l.receive()
+	go l.schedule()
+
+	return l, nil
+}
+
+func getStatsdValue(metric string) (string, error) {
+
+	statsd.Lock()
+	listener := statsd.listener
+	statsd.Unlock()
+
+	if listener == nil {
+		return "", fmt.Errorf("statsd listener is not started")
+	}
+
+	return listener.value(metric), nil
+}
+
+// value returns the metric value, or an empty string if nothing is received yet
+func (l *StatsdListener) value(metric string) string {
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if value, ok := l.gauges[metric]; ok {
+		return formatMetricValue(value)
+	}
+
+	if value, ok := l.flushed[metric]; ok {
+		return formatMetricValue(value)
+	}
+
+	return ""
+}
+
+func (l *StatsdListener) receive() {
+
+	buffer := make([]byte, statsdPacketSize)
+
+	for {
+		n, _, err := l.conn.ReadFrom(buffer)
+		if err != nil {
+			select {
+			case <-l.closed:
+				return
+			default:
+				continue
+			}
+		}
+		for _, line := range strings.Split(string(buffer[:n]), "\n") {
+			l.handle(strings.TrimSpace(line))
+		}
+	}
+}
+
+func (l *StatsdListener) schedule() {
+
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			l.flush()
+		case <-l.closed:
+			return
+		}
+	}
+}
+
+// handle parses a line in "name:value|type[|@rate][|#tags]" format.
+// Malformed lines are ignored, as StatsD daemons usually do
+func (l *StatsdListener) handle(line string) {
+
+	colon := strings.Index(line, ":")
+	if colon <= 0 {
+		return
+	}
+
+	name := line[:colon]
+	fields := strings.Split(line[colon+1:], "|")
+	if len(fields) < 2 {
+		return
+	}
+
+	rawValue, kind := fields[0], fields[1]
+
+	rate := 1.0
+	for _, field := range fields[2:] {
+		if strings.HasPrefix(field, "@") {
+			if r, err := strconv.ParseFloat(field[1:], 64); err == nil && r > 0 && r <= 1 {
+				rate = r
+			}
+		}
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if kind == "s" {
+		if l.sets[name] == nil {
+			l.sets[name] = make(map[string]bool)
+		}
+		l.sets[name][rawValue] = true
+		return
+	}
+
+	value, err := strconv.ParseFloat(rawValue, 64)
+	if err != nil {
+		return
+	}
+
+	switch kind {
+	case "c":
+		l.counters[name] += value / rate
+	case "ms", "h":
+		l.timers[name] = append(l.timers[name], value)
+	case "g":
+		// signed gauge values modify the current value, as in the original StatsD
+		if strings.HasPrefix(rawValue, "+") || strings.HasPrefix(rawValue, "-") {
+			l.gauges[name] += value
+		} else {
+			l.gauges[name] = value
+		}
+	}
+}
+
+// flush publishes the aggregates of the completed interval and starts a new one.
+// Metrics, which were not received during the interval, are reported as zero
+func (l *StatsdListener) flush() {
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	seconds := l.interval.Seconds()
+
+	for name := range l.flushed {
+		l.flushed[name] = 0
+	}
+
+	for name, value := range l.counters {
+		l.flushed[name] = value
+		l.flushed[name+".rate"] = value / seconds
+		delete(l.counters, name)
+	}
+
+	for name, values := range l.timers {
+		for key, value := range aggregateTimer(values) {
+			l.flushed[name+"."+key] = value
+		}
+		l.flushed[name] = l.flushed[name+".mean"]
+		delete(l.timers, name)
+	}
+
+	for name, values := range l.sets {
+		l.flushed[name] = float64(len(values))
+		delete(l.sets, name)
+	}
+}
+
+func (l *StatsdListener) close() {
+	close(l.closed)
+	_ = l.conn.Close()
+}
+
+func aggregateTimer(values []float64) map[string]float64 {
+
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+
+	count := len(sorted)
+	result := map[string]float64{
+		"count":  float64(count),
+		"mean":   sum / float64(count),
+		"median": percentile(sorted, 50),
+		"lower":  sorted[0],
+		"upper":  sorted[count-1],
+		"sum":    sum,
+	}
+
+	for _, p := range []int{90, 95, 99} {
+		result[fmt.Sprintf("p%d", p)] = percentile(sorted, p)
+	}
+
+	return result
+}
+
+// percentile uses the nearest-rank method on sorted values
+func percentile(sorted []float64, p int) float64 {
+	rank := int(math.Ceil(float64(p) / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func formatMetricValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,90 @@
+package data
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestStatsdListener(t *testing.T) {
+
+	listener, err := NewStatsdListener("127.0.0.1:0", time.Hour)
+	if err != nil {
+		t.Fatalf("NewStatsdListener() error = %v", err)
+	}
+	defer listener.close()
+
+	client, err := net.Dial("udp", listener.conn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer client.Close()
+
+	packets := []string{
+		"service.requests:1|c\nservice.requests:2|c|@0.5",
+		"service.latency:10|ms\nservice.latency:30|ms|#env:prod\nservice.latency:20|ms",
+		"service.queue:7|g\nservice.queue:-2|g",
+		"service.users:alice|s\nservice.users:bob|s\nservice.users:alice|s",
+		"malformed\nservice.broken:x|c",
+		"service.done:1|c",
+	}
+
+	for _, packet := range packets {
+		if _, err := client.Write([]byte(packet)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	awaitStatsd(t, func() bool { return listener.value("service.queue") == "5" })
+
+	// the last packet is received, since UDP on the loopback interface preserves the order
+	awaitStatsd(t, func() bool {
+		listener.mutex.Lock()
+		defer listener.mutex.Unlock()
+		return listener.counters["service.done"] == 1
+	})
+
+	if got := listener.value("service.requests"); got != "" {
+		t.Errorf("value() before flush = %q, want empty", got)
+	}
+
+	listener.flush()
+
+	tests := []struct {
+		metric string
+		want   string
+	}{
+		{"service.requests", "5"},
+		{"service.latency", "20"},
+		{"service.latency.count", "3"},
+		{"service.latency.upper", "30"},
+		{"service.latency.p90", "30"},
+		{"service.queue", "5"},
+		{"service.users", "2"},
+		{"service.broken", ""},
+		{"service.unknown", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.metric, func(t *testing.T) {
+			if got := listener.value(tt.metric); got != tt.want {
+				t.Errorf("value() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	listener.flush()
+
+	if got := listener.value("service.requests"); got != "0" {
+		t.Errorf("value() for an idle interval = %q, want %q", got, "0")
+	}
+}
+
+func awaitStatsd(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("statsd metrics are not received in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,185 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sqshq/sampler/config"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Transform represents a single step of the sample transformation pipeline
+type Transform interface {
+	apply(sample string) (string, error)
+}
+
+var unitFactors = map[config.TransformUnit]float64{
+	config.UnitBytesToKiB: 1.0 / 1024,
+	config.UnitBytesToMiB: 1.0 / (1024 * 1024),
+	config.UnitBytesToGiB: 1.0 / (1024 * 1024 * 1024),
+	config.UnitNsToMs:     1.0 / 1000000,
+	config.UnitUsToMs:     1.0 / 1000,
+	config.UnitMsToS:      1.0 / 1000,
+	config.UnitSToMin:     1.0 / 60,
+	config.UnitMsToMin:    1.0 / 60000,
+}
+
+type regexTransform struct {
+	regexp *regexp.Regexp
+}
+
+type jsonPathTransform struct {
+	path []string
+}
+
+type arithmeticTransform struct {
+	multiplier float64
+	addend     float64
+}
+
+type trimTransform struct {
+	cutset string
+}
+
+type scriptTransform struct {
+	script string
+}
+
+// NewTransforms creates the transformation pipeline. The shell script
+// transform, if specified, is executed as the last step
+func NewTransforms(cfgs []config.TransformConfig, script *string) []Transform {
+
+	transforms := make([]Transform, 0)
+
+	for _, cfg := range cfgs {
+		transforms = append(transforms, NewTransform(cfg))
+	}
+
+	if script != nil {
+		transforms = append(transforms, &scriptTransform{script: *script})
+	}
+
+	return transforms
+}
+
+func NewTransform(cfg config.TransformConfig) Transform {
+	switch {
+	case cfg.Regex != nil:
+		return &regexTransform{regexp: regexp.MustCompile(*cfg.Regex)}
+	case cfg.JsonPath != nil:
+		return &jsonPathTransform{path: parseJsonPath(*cfg.JsonPath)}
+	case cfg.Unit != nil:
+		return &arithmeticTransform{multiplier: unitFactors[*cfg.Unit]}
+	case cfg.Multiply != nil:
+		return &arithmeticTransform{multiplier: *cfg.Multiply}
+	case cfg.Add != nil:
+		return &arithmeticTransform{multiplier: 1, addend: *cfg.Add}
+	case cfg.Trim != nil:
+		return &trimTransform{cutset: *cfg.Trim}
+	case cfg.Script != nil:
+		return &scriptTransform{script: *cfg.Script}
+	default:
+		panic("Transform step should have exactly one of the transformations specified")
+	}
+}
+
+// regex transform returns the first capturing group, or the whole match if there are no groups
+func (t *regexTransform) apply(sample string) (string, error) {
+
+	match := t.regexp.FindStringSubmatch(sample)
+
+	if match == nil {
+		return "", fmt.Errorf("transform regex '%s' doesn't match the sample", t.regexp.String())
+	}
+
+	if len(match) > 1 {
+		return match[1], nil
+	}
+
+	return match[0], nil
+}
+
+func (t *jsonPathTransform) apply(sample string) (string, error) {
+
+	decoder := json.NewDecoder(strings.NewReader(sample))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return "", fmt.Errorf("failed to parse sample as json: %v", err)
+	}
+
+	for _, key := range t.path {
+		switch node := value.(type) {
+		case map[string]interface{}:
+			v, ok := node[key]
+			if !ok {
+				return "", fmt.Errorf("json key '%s' is not found", key)
+			}
+			value = v
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node) {
+				return "", fmt.Errorf("json array index '%s' is out of range", key)
+			}
+			value = node[index]
+		default:
+			return "", fmt.Errorf("json key '%s' is not found", key)
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case nil:
+		return "", errors.New("json value is null")
+	default:
+		var buffer bytes.Buffer
+		encoder := json.NewEncoder(&buffer)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(v); err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(buffer.String()), nil
+	}
+}
+
+func (t *arithmeticTransform) apply(sample string) (string, error) {
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(sample), 64)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse a number for transformation: %s", strings.TrimSpace(sample))
+	}
+
+	return strconv.FormatFloat(value*t.multiplier+t.addend, 'f', -1, 64), nil
+}
+
+func (t *trimTransform) apply(sample string) (string, error) {
+	return strings.TrimSpace(strings.Trim(strings.TrimSpace(sample), t.cutset)), nil
+}
+
+func (t *scriptTransform) apply(sample string) (string, error) {
+	return execute([]string{"sample=" + sample}, t.script)
+}
+
+// parseJsonPath splits path like ".data.items[0].value" into keys
+func parseJsonPath(path string) []string {
+
+	var keys []string
+
+	path = strings.Replace(path, "[", ".", -1)
+	path = strings.Replace(path, "]", "", -1)
+
+	for _, key := range strings.Split(path, ".") {
+		if len(key) > 0 {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
@@ -0,0 +1,71 @@
+package data
+
+import (
+	"github.com/sqshq/sampler/config"
+	"testing"
+)
+
+func TestTransform_apply(t *testing.T) {
+
+	regex := `time=(\d+)ms`
+	wholeMatchRegex := `\d+`
+	jsonPath := ".data.items[1].value"
+	unit := config.UnitBytesToMiB
+	multiply := 100.0
+	add := -1.5
+	trim := "%"
+	script := "echo $sample | tr -d '-'"
+
+	tests := []struct {
+		name    string
+		cfg     config.TransformConfig
+		sample  string
+		want    string
+		wantErr bool
+	}{
+		{"should extract regex group", config.TransformConfig{Regex: &regex}, "ping: time=42ms", "42", false},
+		{"should extract whole regex match", config.TransformConfig{Regex: &wholeMatchRegex}, "abc 123 def", "123", false},
+		{"should fail on regex mismatch", config.TransformConfig{Regex: &regex}, "timeout", "", true},
+		{"should extract json value", config.TransformConfig{JsonPath: &jsonPath}, `{"data":{"items":[{"value":1},{"value":12.50}]}}`, "12.50", false},
+		{"should fail on missing json key", config.TransformConfig{JsonPath: &jsonPath}, `{"data":{}}`, "", true},
+		{"should fail on invalid json", config.TransformConfig{JsonPath: &jsonPath}, `not a json`, "", true},
+		{"should convert bytes to MiB", config.TransformConfig{Unit: &unit}, "3145728\n", "3", false},
+		{"should multiply value", config.TransformConfig{Multiply: &multiply}, "0.25", "25", false},
+		{"should add value", config.TransformConfig{Add: &add}, "4", "2.5", false},
+		{"should fail to multiply non-number", config.TransformConfig{Multiply: &multiply}, "abc", "", true},
+		{"should trim cutset and spaces", config.TransformConfig{Trim: &trim}, "  95.5 % \n", "95.5", false},
+		{"should execute shell script", config.TransformConfig{Script: &script}, "-7-", "7\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewTransform(tt.cfg).apply(tt.sample)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("apply() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("apply() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestItem_transform(t *testing.T) {
+
+	jsonPath := "bytes"
+	unit := config.UnitBytesToKiB
+	script := "echo $sample KiB"
+
+	item := &Item{transforms: NewTransforms(
+		[]config.TransformConfig{{JsonPath: &jsonPath}, {Unit: &unit}},
+		&script,
+	)}
+
+	got, err := item.transform(`{"bytes": 2048}`)
+	if err != nil {
+		t.Fatalf("transform() error = %v", err)
+	}
+	if got != "2 KiB\n" {
+		t.Errorf("transform() = %q, want %q", got, "2 KiB\n")
+	}
+}
@@ -79,12 +79,12 @@ func (t *Trigger) Execute(sample *Sample) {
 		}
 
 		if t.actions.visual {
-			t.consumer.AlertChannel <- &Alert{
+			t.consumer.PushAlert(&Alert{
 				Title:       t.title,
-				Text:        fmt.Sprintf("%s: %v", sample.Label, sample.Value),
+				Text:        maskSecrets(fmt.Sprintf("%s: %v", sample.Label, sample.Value)),
 				Color:       sample.Color,
 				Recoverable: false,
-			}
+			})
 		}
 
 		if t.actions.script != nil {
@@ -106,12 +106,12 @@ func (t *Trigger) evaluate(sample *Sample) bool {
 	output, err := t.runScript(t.condition, sample.Label, t.valuesByLabel[sample.Label])
 
 	if err != nil {
-		t.consumer.AlertChannel <- &Alert{
+		t.consumer.PushAlert(&Alert{
 			Title:       "Trigger condition failure",
 			Text:        getErrorMessage(err),
 			Color:       sample.Color,
 			Recoverable: true,
-		}
+		})
 	}
 
 	return t.digitsRegexp.ReplaceAllString(string(output), "") == TrueIndicator
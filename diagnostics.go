@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"github.com/sqshq/sampler/component"
+	"github.com/sqshq/sampler/config"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// componentList collects the started components, when there is no layout
+type componentList []*component.Component
+
+func (l *componentList) AddComponent(cpt *component.Component) {
+	*l = append(*l, cpt)
+}
+
+// diagnose samples all the components without UI for the duration, and prints
+// sampling stats of each item, so the expensive scripts can be found and tuned
+func diagnose(cfg config.Config, opt config.Options, duration time.Duration) {
+
+	var components componentList
+	starter := &Starter{nil, &components, cfg.GetPalette(), opt, cfg}
+
+	fmt.Fprintf(os.Stderr, "Sampling for %v...\n", duration)
+	samplers := starter.startAll(cfg)
+	time.Sleep(duration)
+
+	for _, s := range samplers {
+		s.Stop()
+	}
+
+	printDiagnostics(os.Stdout, components)
+}
+
+func printDiagnostics(output io.Writer, components []*component.Component) {
+
+	writer := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+
+	for _, row := range component.GetDiagnosticsRows(components) {
+		line := strings.Join(row.Cells, "\t")
+		if row.Warning {
+			line += "\t!"
+		}
+		fmt.Fprintln(writer, line)
+	}
+
+	writer.Flush()
+}
@@ -1,7 +1,9 @@
 package event
 
 import (
+	"fmt"
 	ui "github.com/gizak/termui/v3"
+	"github.com/sqshq/sampler/component"
 	"github.com/sqshq/sampler/component/layout"
 	"github.com/sqshq/sampler/config"
 	"github.com/sqshq/sampler/console"
@@ -13,64 +15,126 @@ const (
 	refreshRateToRenderRateRatio = 0.5
 )
 
+// Handler processes console and layout events. Samplers are kept in the same order as layout components
 type Handler struct {
 	samplers      []*data.Sampler
 	options       config.Options
+	config        config.Config
 	layout        *layout.Layout
 	renderTicker  *time.Ticker
 	consoleEvents <-chan ui.Event
 	renderRate    time.Duration
+	start         StartFunc
+	copies        map[*component.Component]string
+	created       map[*component.Component]config.ComponentDraft
+	deleted       []config.ComponentSettings
+	keys          console.KeyMap
+	hoverRender   <-chan time.Time
 }
 
-func NewHandler(samplers []*data.Sampler, options config.Options, layout *layout.Layout) *Handler {
+// StartFunc creates the components, described in config, and starts their samplers
+type StartFunc func(cfg config.Config) []*data.Sampler
+
+func NewHandler(samplers []*data.Sampler, options config.Options, cfg config.Config, layout *layout.Layout, start StartFunc) *Handler {
 	renderRate := calcMinRenderRate(layout)
 	return &Handler{
 		samplers:      samplers,
 		options:       options,
+		config:        cfg,
 		layout:        layout,
+		start:         start,
+		copies:        make(map[*component.Component]string),
+		created:       make(map[*component.Component]config.ComponentDraft),
 		consoleEvents: ui.PollEvents(),
 		renderTicker:  time.NewTicker(renderRate),
 		renderRate:    renderRate,
+		keys:          cfg.GetKeys(),
 	}
 }
 
 func (h *Handler) HandleEvents() {
 
 	// initial render
-	ui.Render(h.layout)
+	console.Render(h.layout)
+	console.SetMouseMotion(h.layout.GetMode() == layout.ModeDefault)
 
 	for {
 		select {
 		case mode := <-h.layout.ChangeModeEvents:
 			h.handleModeChange(mode)
+		case draft := <-h.layout.AddComponentEvents:
+			h.addComponent(draft)
+		case c := <-h.layout.DuplicateComponentEvents:
+			h.duplicateComponent(c)
+		case c := <-h.layout.DeleteComponentEvents:
+			h.deleteComponent(c)
+		case c := <-h.layout.PauseComponentEvents:
+			c.Paused = !c.Paused
+			c.Sampler.Hold(c.Paused)
+		case c := <-h.layout.RefreshComponentEvents:
+			c.Sampler.Refresh()
 		case <-h.renderTicker.C:
-			ui.Render(h.layout)
+			console.Render(h.layout)
+		case <-h.hoverRender:
+			h.hoverRender = nil
+			console.Render(h.layout)
 		case e := <-h.consoleEvents:
 			switch e.ID {
 			case console.SignalClick:
 				payload := e.Payload.(ui.Mouse)
-				h.layout.HandleMouseClick(payload.X, payload.Y)
-			case console.KeyQuit1, console.KeyQuit2, console.KeyQuit3:
-				if h.layout.WerePositionsChanged() {
-					h.updateConfigFile()
+				if payload.Drag {
+					h.layout.HandleMouseDrag(payload.X, payload.Y)
+				} else {
+					h.layout.HandleMouseClick(payload.X, payload.Y)
+				}
+			case console.SignalMouseRelease:
+				// motion without pressed button is reported as a release
+				payload := e.Payload.(ui.Mouse)
+				if payload.Drag {
+					h.layout.HandleMouseHover(payload.X, payload.Y)
+					h.scheduleHoverRender()
+				} else {
+					h.layout.HandleMouseRelease()
 				}
-				return
+			case console.SignalWheelUp:
+				payload := e.Payload.(ui.Mouse)
+				h.layout.HandleMouseWheel(payload.X, payload.Y, -1)
+			case console.SignalWheelDown:
+				payload := e.Payload.(ui.Mouse)
+				h.layout.HandleMouseWheel(payload.X, payload.Y, 1)
 			case console.SignalResize:
 				payload := e.Payload.(ui.Resize)
 				h.layout.ChangeDimensions(payload.Width, payload.Height)
 			default:
+				if h.isQuitKey(e.ID) {
+					if h.layout.WerePositionsChanged() {
+						h.updateConfigFile()
+					}
+					return
+				}
 				h.layout.HandleKeyboardEvent(e.ID)
 			}
 		}
 	}
 }
 
+// isQuitKey checks the key bindings, while in the wizard all keys but <C-c> are typed as text
+func (h *Handler) isQuitKey(key string) bool {
+	if h.layout.IsInputMode() {
+		return key == console.KeyQuit3
+	}
+	return h.keys.GetAction(key) == console.ActionQuit
+}
+
 func (h *Handler) handleModeChange(m layout.Mode) {
 
 	// render the change before switching the tickers
-	ui.Render(h.layout)
+	console.Render(h.layout)
 	h.renderTicker.Stop()
 
+	// hover is handled in the default mode only
+	console.SetMouseMotion(m == layout.ModeDefault)
+
 	switch m {
 	case layout.ModeDefault:
 		h.renderTicker = time.NewTicker(h.renderRate)
@@ -78,25 +142,170 @@ func (h *Handler) handleModeChange(m layout.Mode) {
 	case layout.ModePause:
 		h.pause(true)
 		// proceed with stopped timer
+	case layout.ModeHelp, layout.ModeDiagnostics:
+		// samplers keep running or stay paused, depending on the mode the overlay is opened in
+		h.renderTicker = time.NewTicker(h.renderRate)
 	default:
 		h.renderTicker = time.NewTicker(console.MinRenderInterval)
 		h.pause(false)
 	}
 }
 
+// scheduleHoverRender renders the pinpointed chart soon, without switching the render ticker.
+// Renders are throttled, since the pointer motion is reported on every cell
+func (h *Handler) scheduleHoverRender() {
+	if h.hoverRender == nil {
+		h.hoverRender = time.After(console.MinRenderInterval)
+	}
+}
+
 func (h *Handler) pause(pause bool) {
 	for _, s := range h.samplers {
 		s.Pause(pause)
 	}
 }
 
+// addComponent starts the drafted component right away. Component is saved to the config file on quit
+func (h *Handler) addComponent(draft config.ComponentDraft) {
+
+	var cfg *config.Config
+	err := fmt.Errorf("component titles should be unique. Please rename '%s'", draft.Title)
+	if h.isTitleUnique(draft.Title) {
+		cfg, err = config.CreateComponent(draft, h.getArrangedSettings(), h.config.GetGrid(), h.options)
+	}
+
+	if err == nil {
+		h.config.Merge(cfg)
+		h.samplers = append(h.samplers, h.start(*cfg)...)
+		h.created[h.layout.Components[len(h.layout.Components)-1]] = draft
+		h.renderRate = calcMinRenderRate(h.layout)
+	}
+
+	h.layout.CompleteComponentAdd(err)
+}
+
+// duplicateComponent starts a copy of the component, which is saved to the config file on quit
+func (h *Handler) duplicateComponent(c *component.Component) {
+
+	location, size, err := config.Arrange(h.getArrangedSettings(), h.config.GetGrid())
+	if err != nil {
+		c.PushAlert(&data.Alert{Title: "Duplication failure", Text: err.Error(), Recoverable: true})
+		return
+	}
+
+	// copy of a copy refers to the component, which is present in the config file
+	origin, ok := h.copies[c]
+	if !ok {
+		origin = c.Title
+	}
+
+	settings := config.ComponentSettings{
+		Type: c.Type, Title: h.getCopyTitle(c.Title), Location: location, Size: size, Breakpoint: c.GetBreakpoint()}
+	cfg := h.config.Duplicate(c.Type, c.Title, settings)
+
+	h.config.Merge(cfg)
+	h.samplers = append(h.samplers, h.start(*cfg)...)
+	h.copies[h.layout.Components[len(h.layout.Components)-1]] = origin
+	h.renderRate = calcMinRenderRate(h.layout)
+	h.layout.CompleteComponentDuplicate()
+}
+
+// deleteComponent stops the component sampler. Component is removed from the config file on quit
+func (h *Handler) deleteComponent(c *component.Component) {
+
+	for i, current := range h.layout.Components {
+		if current == c {
+			h.samplers[i].Stop()
+			h.samplers = append(h.samplers[:i], h.samplers[i+1:]...)
+		}
+	}
+
+	h.layout.RemoveComponent(c)
+	h.config.Remove(c.Type, c.Title)
+	h.renderRate = calcMinRenderRate(h.layout)
+
+	if _, ok := h.copies[c]; ok {
+		delete(h.copies, c)
+	} else if draft, ok := h.created[c]; ok {
+		delete(h.created, c)
+		// copies can't refer to the component, which isn't in the config file, so they are saved as new ones
+		for copied, origin := range h.copies {
+			if origin == c.Title {
+				draft := draft
+				draft.Title = copied.Title
+				h.created[copied] = draft
+				delete(h.copies, copied)
+			}
+		}
+	} else {
+		h.deleted = append(h.deleted, config.ComponentSettings{Type: c.Type, Title: c.Title, Deleted: true})
+	}
+}
+
+// getCopyTitle returns a unique title for the copy, e.g. "CPU copy" or "CPU copy 2"
+func (h *Handler) getCopyTitle(title string) string {
+	for i := 1; ; i++ {
+		candidate := title + " copy"
+		if i > 1 {
+			candidate = fmt.Sprintf("%s copy %d", title, i)
+		}
+		if h.isTitleUnique(candidate) {
+			return candidate
+		}
+	}
+}
+
+// isTitleUnique checks the running components, since new ones aren't in the config file until quit
+func (h *Handler) isTitleUnique(title string) bool {
+	for _, c := range h.layout.Components {
+		if c.Title == title {
+			return false
+		}
+	}
+	return true
+}
+
+// updateConfigFile saves positions of all the breakpoints, new components, duplicates and deletions.
+// Positions aren't saved in auto layout, since they are tiled on every start
 func (h *Handler) updateConfigFile() {
 	var settings []config.ComponentSettings
 	for _, c := range h.layout.Components {
-		settings = append(settings,
-			config.ComponentSettings{Type: c.Type, Title: c.Title, Size: c.Size, Location: c.Location})
+		origin, copied := h.copies[c]
+		draft, created := h.created[c]
+		if h.config.IsAutoLayout() && !copied && !created {
+			continue
+		}
+		for _, s := range c.GetPositions() {
+			if copied {
+				s.CopyOf = &origin
+			}
+			if created {
+				draft := draft
+				s.Draft = &draft
+			}
+			settings = append(settings, s)
+		}
+	}
+	config.Update(append(settings, h.deleted...), h.options)
+}
+
+// getSettings returns the current positions, which new components are arranged among
+func (h *Handler) getSettings() []config.ComponentSettings {
+	var settings []config.ComponentSettings
+	for _, c := range h.layout.Components {
+		s := config.ComponentSettings{Type: c.Type, Title: c.Title, Size: c.Size, Location: c.Location, Breakpoint: c.GetBreakpoint()}
+		settings = append(settings, s)
+	}
+	return settings
+}
+
+// getArrangedSettings returns the settings, which new components are arranged among.
+// There are none in auto layout, because components are tiled after the change
+func (h *Handler) getArrangedSettings() []config.ComponentSettings {
+	if h.config.IsAutoLayout() {
+		return nil
 	}
-	config.Update(settings, h.options)
+	return h.getSettings()
 }
 
 func calcMinRenderRate(layout *layout.Layout) time.Duration {
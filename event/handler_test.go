@@ -0,0 +1,87 @@
+package event
+
+import (
+	"github.com/sqshq/sampler/component"
+	"github.com/sqshq/sampler/component/layout"
+	"github.com/sqshq/sampler/config"
+	"github.com/sqshq/sampler/data"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const savedConfig = `runcharts:
+  - title: Saved
+    position: [[0, 0], [40, 20]]
+    rate-ms: 1000
+    items:
+      - label: value
+        sample: echo 1
+`
+
+func newTestHandler(t *testing.T) *Handler {
+
+	file := filepath.Join(t.TempDir(), "config.yml")
+	if err := ioutil.WriteFile(file, []byte(savedConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Config{}
+	options := config.Options{ConfigFile: &file}
+
+	// the layout and the components aren't drawn, so they are started without the terminal
+	lout := &layout.Layout{ChangeModeEvents: make(chan layout.Mode, 10)}
+	start := func(cfg config.Config) []*data.Sampler {
+		var samplers []*data.Sampler
+		for _, c := range cfg.RunCharts {
+			consumer := data.NewConsumer()
+			cpt := component.NewComponent(nil, consumer, c.ComponentConfig)
+			cpt.Sampler = data.NewSampler(consumer, nil, nil, nil, options, nil, *c.RateMs)
+			lout.AddComponent(cpt)
+			samplers = append(samplers, cpt.Sampler)
+		}
+		return samplers
+	}
+
+	return &Handler{
+		options: options,
+		config:  cfg,
+		layout:  lout,
+		start:   start,
+		copies:  make(map[*component.Component]string),
+		created: make(map[*component.Component]config.ComponentDraft),
+	}
+}
+
+func TestHandler_deleteCreatedOrigin(t *testing.T) {
+
+	h := newTestHandler(t)
+
+	h.addComponent(config.ComponentDraft{Type: config.TypeRunChart, Title: "New", Sample: "echo 2", RateMs: 500})
+	created := h.layout.Components[0]
+	h.duplicateComponent(created)
+	h.deleteComponent(created)
+	h.updateConfigFile()
+
+	for _, s := range h.samplers {
+		s.Stop()
+	}
+
+	content, err := ioutil.ReadFile(*h.options.ConfigFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// copy is saved with the settings of the deleted component
+	saved := string(content)
+	if !strings.HasPrefix(saved, savedConfig) {
+		t.Errorf("saved component was changed:\n%s", saved)
+	}
+	if !strings.Contains(saved, "title: New copy") || !strings.Contains(saved, "sample: echo 2") {
+		t.Errorf("copy wasn't saved:\n%s", saved)
+	}
+	if strings.Contains(saved, "title: New\n") {
+		t.Errorf("deleted component was saved:\n%s", saved)
+	}
+}
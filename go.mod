@@ -8,9 +8,12 @@ require (
 	github.com/hajimehoshi/oto v0.1.1
 	github.com/jessevdk/go-flags v1.4.0
 	github.com/kr/pty v1.1.5
+	github.com/lib/pq v1.9.0
 	github.com/lunixbochs/vtclean v1.0.0
-	github.com/mattn/go-runewidth v0.0.4
+	github.com/mattn/go-runewidth v0.0.9
+	github.com/mattn/go-sqlite3 v1.14.6
 	github.com/mbndr/figlet4go v0.0.0-20190224160619-d6cef5b186ea
+	github.com/nsf/termbox-go v1.1.1
 	gopkg.in/yaml.v3 v3.0.0-20190709130402-674ba3eaed22
 )
 
@@ -18,9 +21,5 @@ require (
 	github.com/cjbassi/drawille-go v0.0.0-20190126131713-27dc511fe6fd // indirect
 	github.com/gopherjs/gopherjs v0.0.0-20180628210949-0892b62f0d9f // indirect
 	github.com/gopherjs/gopherwasm v0.1.1 // indirect
-	github.com/mitchellh/go-homedir v1.1.0 // indirect
 	github.com/mitchellh/go-wordwrap v1.0.0 // indirect
-	github.com/nsf/termbox-go v0.0.0-20190121233118-02980233997d // indirect
-	golang.org/x/arch v0.0.0-20181203225421-5a4828bb7045 // indirect
-	gopkg.in/check.v1 v0.0.0-20161208181325-20d25e280405 // indirect
 )
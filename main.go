@@ -18,37 +18,44 @@ import (
 	"time"
 )
 
+// componentAdder places the started components, which is the layout, unless sampling is headless
+type componentAdder interface {
+	AddComponent(cpt *component.Component)
+}
+
 type Starter struct {
 	player  *asset.AudioPlayer
-	lout    *layout.Layout
+	lout    componentAdder
 	palette console.Palette
 	opt     config.Options
 	cfg     config.Config
 }
 
-func (s *Starter) startAll() []*data.Sampler {
+// startAll starts components of the config. Variables are taken from the initial config,
+// since they are already resolved
+func (s *Starter) startAll(cfg config.Config) []*data.Sampler {
 	samplers := make([]*data.Sampler, 0)
-	for _, c := range s.cfg.RunCharts {
+	for _, c := range cfg.RunCharts {
 		cpt := runchart.NewRunChart(c, s.palette)
 		samplers = append(samplers, s.start(cpt, cpt.Consumer, c.ComponentConfig, c.Items, c.Triggers))
 	}
-	for _, c := range s.cfg.SparkLines {
+	for _, c := range cfg.SparkLines {
 		cpt := sparkline.NewSparkLine(c, s.palette)
 		samplers = append(samplers, s.start(cpt, cpt.Consumer, c.ComponentConfig, []config.Item{c.Item}, c.Triggers))
 	}
-	for _, c := range s.cfg.BarCharts {
+	for _, c := range cfg.BarCharts {
 		cpt := barchart.NewBarChart(c, s.palette)
 		samplers = append(samplers, s.start(cpt, cpt.Consumer, c.ComponentConfig, c.Items, c.Triggers))
 	}
-	for _, c := range s.cfg.Gauges {
+	for _, c := range cfg.Gauges {
 		cpt := gauge.NewGauge(c, s.palette)
 		samplers = append(samplers, s.start(cpt, cpt.Consumer, c.ComponentConfig, []config.Item{c.Cur, c.Min, c.Max}, c.Triggers))
 	}
-	for _, c := range s.cfg.AsciiBoxes {
+	for _, c := range cfg.AsciiBoxes {
 		cpt := asciibox.NewAsciiBox(c, s.palette)
 		samplers = append(samplers, s.start(cpt, cpt.Consumer, c.ComponentConfig, []config.Item{c.Item}, c.Triggers))
 	}
-	for _, c := range s.cfg.TextBoxes {
+	for _, c := range cfg.TextBoxes {
 		cpt := textbox.NewTextBox(c, s.palette)
 		samplers = append(samplers, s.start(cpt, cpt.Consumer, c.ComponentConfig, []config.Item{c.Item}, c.Triggers))
 	}
@@ -59,16 +66,46 @@ func (s *Starter) start(drawable ui.Drawable, consumer *data.Consumer, component
 	cpt := component.NewComponent(drawable, consumer, componentConfig)
 	triggers := data.NewTriggers(triggersConfig, consumer, s.opt, s.player)
 	items := data.NewItems(itemsConfig, *componentConfig.RateMs)
+	cpt.Sampler = data.NewSampler(consumer, items, triggers, componentConfig.Source, s.opt, s.cfg.Variables, *componentConfig.RateMs)
 	s.lout.AddComponent(cpt)
 	time.Sleep(10 * time.Millisecond) // desync coroutines
-	return data.NewSampler(consumer, items, triggers, s.opt, s.cfg.Variables, *componentConfig.RateMs)
+	return cpt.Sampler
 }
 
 func main() {
 
 	cfg, opt := config.LoadConfig()
 
-	console.Init()
+	// secret values are resolved in memory only, so they are never saved to the config file
+	variables, environment, err := data.ResolveSecrets(cfg.Variables, opt.Environment)
+	if err != nil {
+		console.Exit(err.Error())
+	}
+	resolved := *cfg
+	resolved.Variables = variables
+	opt.Environment = environment
+
+	if cfg.Statsd != nil {
+		if err := data.StartStatsd(*cfg.Statsd); err != nil {
+			console.Exit(err.Error())
+		}
+		defer data.CloseStatsd()
+	}
+
+	colorMode := console.DetectColorMode()
+	if opt.Color != nil {
+		colorMode = *opt.Color
+	}
+
+	defer data.CloseSshConnections()
+	defer data.CloseSqlConnections()
+
+	if opt.Diagnose != nil {
+		diagnose(resolved, opt, *opt.Diagnose)
+		return
+	}
+
+	console.Init(colorMode)
 	defer console.Close()
 
 	player := asset.NewAudioPlayer()
@@ -76,12 +113,14 @@ func main() {
 		defer player.Close()
 	}
 
-	palette := console.GetPalette(*cfg.Theme)
-	lout := layout.NewLayout(component.NewStatusBar(*opt.ConfigFile, palette), component.NewMenu(palette))
+	palette := cfg.GetPalette()
+	keys := cfg.GetKeys()
+	lout := layout.NewLayout(component.NewStatusBar(*opt.ConfigFile, palette, keys), component.NewMenu(palette, keys),
+		component.NewWizard(palette), component.NewHelp(*opt.ConfigFile, palette, keys), component.NewDiagnostics(palette, keys), cfg)
 
-	starter := &Starter{player, lout, palette, opt, *cfg}
-	samplers := starter.startAll()
+	starter := &Starter{player, lout, palette, opt, resolved}
+	samplers := starter.startAll(resolved)
 
-	handler := event.NewHandler(samplers, opt, lout)
+	handler := event.NewHandler(samplers, opt, resolved, lout, starter.startAll)
 	handler.HandleEvents()
 }